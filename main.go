@@ -1,28 +1,48 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
 
 	"slava0135/gobber/constraints"
 	"slava0135/gobber/subtypes"
 	"slava0135/gobber/symexec"
 )
 
-func main() {
-	runNumbers := flag.Bool("numbers", false, "solve numbers constraints")
-	runComplex := flag.Bool("complex", false, "solve complex constraints")
-	runArrays := flag.Bool("arrays", false, "solve arrays constraints")
-	runPushPop := flag.Bool("pushpop", false, "solve constraints with push-pop incrementality")
-	runSoft := flag.Bool("soft", false, "solve soft constraints")
+const (
+	exitOk      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage:
+  gobber gen [flags] <packages or files>      analyze and generate tests
+  gobber analyze [flags] <packages or files>  analyze without generating tests
+  gobber demo <name>                          run bundled example
+
+Arguments ending with '.go' are files, each analyzed as a separate package,
+everything else is a package pattern as in 'go build', e.g. './pkg/...'.
 
-	runSubtypes := flag.Bool("subtypes", false, "subtyping encoding")
+Exit code is 1 if any package or function could not be analyzed, 2 on usage errors.
 
-	runStatic := flag.Bool("static", false, "static symbolic execution")
-	runDynamic := flag.Bool("dynamic", false, "dynamic symbolic execution")
+Run 'gobber <command> -h' for command flags.
+`
 
-	flag.Parse()
+type demo struct {
+	name string
+	help string
+	run  func()
+}
 
-	if *runNumbers {
+var demos = []demo{
+	{"numbers", "solve numbers constraints", func() {
 		constraints.IntegerOperations()
 		constraints.FloatOperations()
 		constraints.MixedOperations()
@@ -31,40 +51,309 @@ func main() {
 		constraints.AdvancedBitwise()
 		constraints.CombinedBitwise()
 		constraints.NestedBitwise()
-	}
-
-	if *runComplex {
+	}},
+	{"complex", "solve complex constraints", func() {
 		constraints.BasicComplexOperations()
 		constraints.ComplexMagnitude()
 		constraints.ComplexComparison()
 		constraints.ComplexOperations()
 		constraints.NestedComplexOperations()
-	}
-
-	if *runArrays {
+	}},
+	{"arrays", "solve arrays constraints", func() {
 		constraints.CompareElement()
 		constraints.CompareAge()
+	}},
+	{"pushpop", "solve constraints with push-pop incrementality", constraints.PushPopIncrementality},
+	{"soft", "solve soft constraints", constraints.CompareAndIncrement},
+	{"subtypes", "subtyping encoding", func() {
+		subtypes.SubclassesExample()
+		subtypes.SubtypesExample()
+		subtypes.NaiveTypeSolver()
+	}},
+	{"static", "static symbolic execution of testdata", symexec.Static},
+	{"dynamic", "dynamic symbolic execution of testdata", symexec.Dynamic},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "gen":
+		return runAnalyze(args[0], args[1:], true)
+	case "analyze":
+		return runAnalyze(args[0], args[1:], false)
+	case "demo":
+		return runDemo(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return exitOk
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", args[0], usage)
+		return exitUsage
 	}
+}
 
-	if *runPushPop {
-		constraints.PushPopIncrementality()
+func runAnalyze(cmd string, args []string, generate bool) int {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	configFile := flags.String("config", "", "read config from `file` instead of "+symexec.ConfigFilename+" next to every input or in its parents")
+	noConfig := flags.Bool("no-config", false, "ignore "+symexec.ConfigFilename)
+	strategy := flags.String("strategy", symexec.StrategyRandom.String(), "path exploration strategy: random, bfs or dfs")
+	maxDepth := flags.Int("max-depth", symexec.DefaultMaxDepth, "maximum number of executed blocks on a single path")
+	preemptions := flags.Int("preemptions", symexec.DefaultPreemptions, "maximum number of goroutine preemptions on a single path")
+	timeout := flags.Duration("timeout", symexec.DefaultSolverTimeout, "solver timeout for a single query")
+	integers := flags.String("integers", symexec.IntegersMath.String(), "integer encoding: math (never overflow) or bitvector (wrap around like in Go)")
+	logLevel := flags.String("log", symexec.LogQuiet.String(), "log `level` to stderr: quiet, info, debug or trace")
+	logJSON := flags.Bool("log-json", false, "log as JSON, one object per line")
+	var include, exclude regexpList
+	flags.Var(&include, "run", "analyze only functions matching `regexp` ('Func' or 'Type.Method'), can be repeated")
+	flags.Var(&exclude, "skip", "skip functions matching `regexp`, can be repeated")
+	exportedOnly := flags.Bool("exported", false, "analyze only exported functions and methods of exported types")
+	jsonReport := flags.String("json", "", "write JSON report to `file` ('-' for stdout)")
+	cover := flags.Bool("cover", false, "print source lines not covered by testcases and why they were not reached")
+	genOpts := symexec.GenerateOptions{}
+	var style *string
+	if generate {
+		style = flags.String("style", symexec.StyleFunctions.String(), "test style: functions (one per testcase) or subtests (one per function)")
+		flags.BoolVar(&genOpts.DryRun, "n", false, "print generated tests instead of writing them")
+		flags.BoolVar(&genOpts.Diff, "diff", false, "print diff against existing tests instead of writing them")
+		flags.BoolVar(&genOpts.Force, "force", false, "overwrite test files that were not generated by gobber")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOk
+		}
+		return exitUsage
 	}
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "gobber %s: no packages or files given\n", cmd)
+		return exitUsage
+	}
+
+	// flags given explicitly take precedence over config
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
 
-	if *runSoft {
-		constraints.CompareAndIncrement()
+	// config is looked up next to every input unless it is given
+	opts := symexec.Options{
+		Include:      include,
+		Exclude:      exclude,
+		ExportedOnly: *exportedOnly,
+		ConfigFile:   *configFile,
+		NoConfig:     *noConfig,
+	}
+	var err error
+	if set["max-depth"] {
+		opts.MaxDepth = *maxDepth
+	}
+	if set["preemptions"] {
+		opts.Preemptions = *preemptions
+	}
+	if set["timeout"] {
+		opts.SolverTimeout = *timeout
+	}
+	if set["strategy"] {
+		if opts.Strategy, err = symexec.ParseStrategy(*strategy); err != nil {
+			fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+			return exitUsage
+		}
+	}
+	if set["integers"] {
+		if opts.Integers, err = symexec.ParseIntegers(*integers); err != nil {
+			fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+			return exitUsage
+		}
+	}
+	if generate && set["style"] {
+		if genOpts.Style, err = symexec.ParseTestStyle(*style); err != nil {
+			fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+			return exitUsage
+		}
+	}
+	if genOpts.DryRun && genOpts.Diff {
+		fmt.Fprintf(os.Stderr, "gobber %s: -n and -diff can't be used together\n", cmd)
+		return exitUsage
+	}
+	level, err := symexec.ParseLogLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+		return exitUsage
+	}
+	opts.Logger = symexec.NewLogger(os.Stderr, level, *logJSON)
+	for _, arg := range flags.Args() {
+		if strings.HasSuffix(arg, ".go") {
+			opts.Files = append(opts.Files, arg)
+		} else {
+			opts.Packages = append(opts.Packages, arg)
+		}
 	}
 
-	if *runSubtypes {
-		subtypes.SubclassesExample()
-		subtypes.SubtypesExample()
-		subtypes.NaiveTypeSolver()
+	res, err := symexec.NewAnalyzer(opts).Analyze()
+	code := exitOk
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+		code = exitFailure
+	}
+	if res == nil {
+		return code
+	}
+	// generated tests or report may be printed to stdout, summary is only the main output of analyze
+	summary := os.Stdout
+	if generate || *jsonReport == "-" {
+		summary = os.Stderr
+	}
+	if !printSummary(summary, res, *cover) {
+		code = exitFailure
+	}
+	if blocked := res.Unsupported(); len(blocked) > 0 {
+		printBlocked(summary, blocked)
+	}
+	// config of package is used unless flags are given
+	pkgGenOpts := func(pkg *symexec.PackageResult) symexec.GenerateOptions {
+		pkgOpts := genOpts
+		if pkg.Config != nil && !set["style"] {
+			pkgOpts.Style = pkg.Config.Output.Style
+		}
+		return pkgOpts
+	}
+	if *jsonReport != "" {
+		style := func(pkg *symexec.PackageResult) symexec.TestStyle {
+			return pkgGenOpts(pkg).Style
+		}
+		if err := writeReport(*jsonReport, res, style); err != nil {
+			fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+			code = exitFailure
+		}
+	}
+	if generate {
+		for _, pkg := range res.Packages {
+			if err := symexec.GenerateTests(pkg, pkgGenOpts(pkg)); err != nil {
+				fmt.Fprintf(os.Stderr, "gobber %s: %s\n", cmd, err)
+				code = exitFailure
+			}
+		}
+	}
+	return code
+}
+
+type regexpList []*regexp.Regexp
+
+func (l *regexpList) String() string {
+	var s []string
+	for _, re := range *l {
+		s = append(s, re.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+func (l *regexpList) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+func writeReport(filename string, res *symexec.Result, style func(pkg *symexec.PackageResult) symexec.TestStyle) error {
+	report := symexec.NewReport(res, style)
+	if filename == "-" {
+		return report.WriteJSON(os.Stdout)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
+	return report.WriteJSON(f)
+}
 
-	if *runStatic {
-		symexec.Static()
+// printSummary prints number of testcases for every function, returns false if any function failed.
+func printSummary(w io.Writer, res *symexec.Result, cover bool) bool {
+	ok := true
+	for _, pkg := range res.Packages {
+		fmt.Fprintf(w, "%s\n", pkg.Input)
+		for _, fr := range pkg.Functions {
+			if fr.Err != nil {
+				ok = false
+				fmt.Fprintf(w, "  FAIL %s: %s\n", fr.Function, fr.Err)
+			} else {
+				blocks, allBlocks := fr.Coverage.CoveredBlocks()
+				branches, allBranches := fr.Coverage.CoveredBranches()
+				fmt.Fprintf(w, "  ok   %s: %d testcases, %d/%d blocks, %d/%d branches\n",
+					fr.Function, len(fr.Testcases), blocks, allBlocks, branches, allBranches)
+				deadlocks, goPanics := 0, 0
+				for _, tc := range fr.Testcases {
+					if tc.Deadlock() {
+						deadlocks++
+					}
+					if tc.GoPanic() {
+						goPanics++
+					}
+				}
+				if deadlocks > 0 {
+					fmt.Fprintf(w, "         %d paths end in deadlock\n", deadlocks)
+				}
+				if goPanics > 0 {
+					fmt.Fprintf(w, "         %d paths end in goroutine panic\n", goPanics)
+				}
+			}
+			if cover {
+				printUncovered(w, fr.Coverage)
+			}
+		}
 	}
+	return ok
+}
 
-	if *runDynamic {
-		symexec.Dynamic()
+// printUncovered prints ranges of uncovered lines with the same reason.
+func printUncovered(w io.Writer, c *symexec.Coverage) {
+	uncovered := c.UncoveredLines()
+	lines := slices.Sorted(maps.Keys(uncovered))
+	for i := 0; i < len(lines); i++ {
+		start, reason := lines[i], uncovered[lines[i]]
+		for i+1 < len(lines) && lines[i+1] == lines[i]+1 && uncovered[lines[i+1]] == reason {
+			i++
+		}
+		if start == lines[i] {
+			fmt.Fprintf(w, "         line %d: %s\n", start, reason)
+		} else {
+			fmt.Fprintf(w, "         lines %d-%d: %s\n", start, lines[i], reason)
+		}
+	}
+}
+
+func printBlocked(w io.Writer, blocked []symexec.BlockedFeature) {
+	fmt.Fprintln(w, "\nanalysis was blocked by:")
+	for _, b := range blocked {
+		var names []string
+		for _, fn := range b.Functions {
+			names = append(names, symexec.FunctionName(fn))
+		}
+		fmt.Fprintf(w, "  %s (%d): %s\n", b.Feature, len(b.Functions), strings.Join(names, ", "))
+	}
+}
+
+func runDemo(args []string) int {
+	idx := -1
+	if len(args) == 1 {
+		idx = slices.IndexFunc(demos, func(d demo) bool { return d.name == args[0] })
+	}
+	if idx < 0 {
+		fmt.Fprintln(os.Stderr, "Usage: gobber demo <name>")
+		fmt.Fprintln(os.Stderr)
+		for _, d := range demos {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", d.name, d.help)
+		}
+		return exitUsage
 	}
+	demos[idx].run()
+	return exitOk
 }
@@ -1,9 +1,12 @@
+// Package symbolic is used in mocks, calls are interpreted by analyzer and must not be executed.
 package symbolic
 
+// MakeSymbolic returns value that can be anything.
 func MakeSymbolic[T any]() T {
 	panic("")
 }
 
+// Assume constrains symbolic values, paths where condition is false are not explored.
 func Assume(bool) {
 	panic("")
-} 
+}
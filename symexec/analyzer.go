@@ -0,0 +1,392 @@
+package symexec
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	DefaultMaxDepth      = 100
+	DefaultPreemptions   = 2
+	DefaultSolverTimeout = 15 * time.Second
+)
+
+// Strategy defines in which order paths are explored.
+type Strategy int
+
+const (
+	// StrategyDefault is StrategyRandom unless strategy is set in config.
+	StrategyDefault Strategy = iota
+	StrategyRandom
+	StrategyBFS
+	StrategyDFS
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case StrategyDefault:
+		return "default"
+	case StrategyRandom:
+		return "random"
+	case StrategyBFS:
+		return "bfs"
+	case StrategyDFS:
+		return "dfs"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// ParseStrategy is the inverse of Strategy.String.
+func ParseStrategy(s string) (Strategy, error) {
+	for _, strategy := range []Strategy{StrategyRandom, StrategyBFS, StrategyDFS} {
+		if strategy.String() == s {
+			return strategy, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown strategy '%s'", s)
+}
+
+func (s *Strategy) UnmarshalText(text []byte) error {
+	strategy, err := ParseStrategy(string(text))
+	if err != nil {
+		return err
+	}
+	*s = strategy
+	return nil
+}
+
+func (s Strategy) newQueue() Queue {
+	switch s {
+	case StrategyBFS:
+		return &BFSQueue{}
+	case StrategyDFS:
+		return &DFSQueue{}
+	default:
+		return &RandomQueue{}
+	}
+}
+
+// Integers defines how integers are encoded.
+type Integers int
+
+const (
+	// IntegersDefault is IntegersMath unless encoding is set in config.
+	IntegersDefault Integers = iota
+	// IntegersMath encodes integers as mathematical integers in range of their type, arithmetic never overflows.
+	IntegersMath
+	// IntegersBitVector encodes integers as bit-vectors of their width, arithmetic wraps around like in Go.
+	IntegersBitVector
+)
+
+func (i Integers) String() string {
+	switch i {
+	case IntegersDefault:
+		return "default"
+	case IntegersMath:
+		return "math"
+	case IntegersBitVector:
+		return "bitvector"
+	default:
+		return fmt.Sprintf("Integers(%d)", int(i))
+	}
+}
+
+// ParseIntegers is the inverse of Integers.String.
+func ParseIntegers(s string) (Integers, error) {
+	for _, integers := range []Integers{IntegersMath, IntegersBitVector} {
+		if integers.String() == s {
+			return integers, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown integers encoding '%s'", s)
+}
+
+func (i *Integers) UnmarshalText(text []byte) error {
+	integers, err := ParseIntegers(string(text))
+	if err != nil {
+		return err
+	}
+	*i = integers
+	return nil
+}
+
+type Options struct {
+	// Files are analyzed one at a time, each file as a separate package.
+	Files []string
+	// Packages are package patterns as accepted by 'go build', e.g. './pkg/...'.
+	Packages []string
+	// Dir is where files and packages are looked up, current directory if empty.
+	Dir string
+
+	// Include selects functions by name ('Func' or 'Type.Method'), all functions are selected if empty.
+	Include []*regexp.Regexp
+	// Exclude skips functions by name, it takes precedence over Include.
+	Exclude []*regexp.Regexp
+	// ExportedOnly skips unexported functions and methods of unexported types.
+	ExportedOnly bool
+
+	// Strategy, MaxDepth, Preemptions and SolverTimeout override config when set.
+	Strategy Strategy
+	MaxDepth int
+	// Preemptions is the maximum number of times running goroutine is switched while it could continue.
+	Preemptions   int
+	SolverTimeout time.Duration
+	// Integers overrides config when set.
+	Integers Integers
+
+	// Config is used instead of gobber.yaml when set.
+	Config *Config
+	// ConfigFile is used instead of gobber.yaml found next to the input or in its parents up to module root.
+	ConfigFile string
+	// NoConfig disables looking up gobber.yaml.
+	NoConfig bool
+
+	// Logger receives progress messages, nothing is logged if nil. See NewLogger.
+	Logger *slog.Logger
+}
+
+type Analyzer struct {
+	opts   Options
+	logger *slog.Logger
+
+	// configs by filename, nil if there is no config
+	configs map[string]*Config
+	config  *Config
+
+	// settings, stats and unreached blocks of function being analyzed
+	current   functionSettings
+	stats     SolverStats
+	unreached *unreachedLog
+	// dynamicTypes of interface values and functions used as values in program being analyzed
+	dynamicTypes []types.Type
+	funcValues   []*ssa.Function
+}
+
+type SolverStats struct {
+	Queries int `json:"queries"`
+	Sat     int `json:"sat"`
+	Unsat   int `json:"unsat"`
+	// Unknown queries are timeouts and solver errors.
+	Unknown int           `json:"unknown"`
+	Time    time.Duration `json:"time_ns"`
+}
+
+type Result struct {
+	Packages []*PackageResult
+}
+
+type PackageResult struct {
+	// Input is the file or the import path of the package.
+	Input     string
+	Package   *ssa.Package
+	Functions []*FunctionResult
+	// Config is the config of the input, nil if there is none.
+	Config *Config
+}
+
+type FunctionResult struct {
+	Function  *ssa.Function
+	Testcases []Testcase
+	// Err is set if analysis was aborted, Testcases is nil then.
+	Err      error
+	Stats    SolverStats
+	Coverage *Coverage
+}
+
+func NewAnalyzer(opts Options) *Analyzer {
+	logger := opts.Logger
+	if logger == nil {
+		logger = NewLogger(nil, LogQuiet, false)
+	}
+	return &Analyzer{opts: opts, logger: logger, configs: make(map[string]*Config), config: opts.Config}
+}
+
+// Analyze runs dynamic symbolic execution on every input file and package.
+func (a *Analyzer) Analyze() (*Result, error) {
+	res := &Result{}
+	for _, filename := range a.opts.Files {
+		pkg, err := a.AnalyzeFile(filename)
+		if err != nil {
+			return res, err
+		}
+		res.Packages = append(res.Packages, pkg)
+	}
+	if len(a.opts.Packages) > 0 {
+		pkgs, err := a.AnalyzePackages(a.opts.Packages...)
+		if err != nil {
+			return res, err
+		}
+		res.Packages = append(res.Packages, pkgs...)
+	}
+	return res, nil
+}
+
+func (a *Analyzer) AnalyzeFile(filename string) (*PackageResult, error) {
+	if err := a.loadConfig(filepath.Dir(filepath.Join(a.opts.Dir, filename))); err != nil {
+		return nil, err
+	}
+	a.logger.Info("building SSA graph", "file", filename)
+	pkgs, err := loadPackages(a.opts.Dir, filename)
+	if err != nil {
+		return nil, err
+	}
+	return a.analyze(filename, pkgs[0]), nil
+}
+
+func (a *Analyzer) AnalyzePackages(patterns ...string) ([]*PackageResult, error) {
+	a.logger.Info("building SSA graph", "packages", strings.Join(patterns, " "))
+	pkgs, err := loadPackages(a.opts.Dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var res []*PackageResult
+	for _, pkg := range pkgs {
+		// every package has config next to it or in its parents
+		if err := a.loadConfig(packageDir(pkg, a.opts.Dir)); err != nil {
+			return res, err
+		}
+		res = append(res, a.analyze(pkg.Pkg.Path(), pkg))
+	}
+	return res, nil
+}
+
+// packageDir is the directory of package source files, dir if package has no files.
+func packageDir(pkg *ssa.Package, dir string) string {
+	for _, v := range pkg.Members {
+		if pos := pkg.Prog.Fset.Position(v.Pos()); pos.Filename != "" {
+			return filepath.Dir(pos.Filename)
+		}
+	}
+	return dir
+}
+
+func (a *Analyzer) analyze(input string, pkg *ssa.Package) *PackageResult {
+	res := &PackageResult{Input: input, Package: pkg, Config: a.config}
+	a.dynamicTypes = dynamicTypes(pkg.Prog)
+	a.funcValues = funcValues(pkg.Prog)
+	for _, fn := range packageFunctions(pkg) {
+		settings := a.settings(fn)
+		if settings.skip || !a.selected(fn) {
+			continue
+		}
+		a.current = settings
+		a.stats = SolverStats{}
+		a.unreached = newUnreachedLog()
+		testcases, err := a.dynamicFunction(fn, pkg)
+		res.Functions = append(res.Functions, &FunctionResult{
+			Function:  fn,
+			Testcases: testcases,
+			Err:       err,
+			Stats:     a.stats,
+			Coverage:  newCoverage(fn, testcases, a.unreached, err != nil),
+		})
+	}
+	return res
+}
+
+// loadConfig sets config for inputs in dir.
+func (a *Analyzer) loadConfig(dir string) error {
+	var err error
+	filename := a.opts.ConfigFile
+	switch {
+	case a.opts.Config != nil:
+		a.config = a.opts.Config
+		return nil
+	case a.opts.NoConfig:
+		a.config = nil
+		return nil
+	case filename == "":
+		filename, err = FindConfig(dir)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg, ok := a.configs[filename]; ok {
+		a.config = cfg
+		return nil
+	}
+	var cfg *Config
+	if filename != "" {
+		a.logger.Info("loading config", "file", filename)
+		cfg, err = LoadConfig(filename)
+		if err != nil {
+			return err
+		}
+	}
+	a.configs[filename] = cfg
+	a.config = cfg
+	return nil
+}
+
+// packageFunctions lists functions and methods declared in package, in source order.
+func packageFunctions(pkg *ssa.Package) []*ssa.Function {
+	var fns []*ssa.Function
+	for _, v := range pkg.Members {
+		if fn, ok := v.(*ssa.Function); ok && fn.Name() != "init" {
+			fns = append(fns, fn)
+		}
+		if obj, ok := v.(*ssa.Type); ok {
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			n := named.NumMethods()
+			for i := 0; i < n; i++ {
+				fns = append(fns, pkg.Prog.FuncValue(named.Method(i)))
+			}
+		}
+	}
+	sort.Slice(fns, func(i, j int) bool {
+		return fns[i].Pos() < fns[j].Pos()
+	})
+	return fns
+}
+
+// FunctionName returns 'Func' for functions and 'Type.Method' for methods.
+func FunctionName(fn *ssa.Function) string {
+	if recv := fn.Signature.Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			return named.Obj().Name() + "." + fn.Name()
+		}
+	}
+	return fn.Name()
+}
+
+func (a *Analyzer) selected(fn *ssa.Function) bool {
+	name := FunctionName(fn)
+	if a.opts.ExportedOnly {
+		for _, segment := range strings.Split(name, ".") {
+			if !token.IsExported(segment) {
+				return false
+			}
+		}
+	}
+	for _, re := range a.opts.Exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(a.opts.Include) == 0 {
+		return true
+	}
+	for _, re := range a.opts.Include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package symexec
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func selectedNames(t *testing.T, opts Options, filename string) []string {
+	pkgs, err := loadPackages("", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAnalyzer(opts)
+	var names []string
+	for _, fn := range packageFunctions(pkgs[0]) {
+		if a.selected(fn) {
+			names = append(names, FunctionName(fn))
+		}
+	}
+	return names
+}
+
+func TestSelect_All(t *testing.T) {
+	got := selectedNames(t, Options{}, "numbers.go")
+	want := []string{"integerOperations", "floatOperations", "mixedOperations", "nestedConditions", "bitwiseOperations", "advancedBitwise", "combinedBitwise", "nestedBitwise"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestSelect_IncludeExclude(t *testing.T) {
+	opts := Options{
+		Include: []*regexp.Regexp{regexp.MustCompile(`^InvokeExample\.Update`), regexp.MustCompile(`DivBy$`)},
+		Exclude: []*regexp.Regexp{regexp.MustCompile(`^InvokeClass\.DivBy$`)},
+	}
+	got := selectedNames(t, opts, "invokes/simpleCalls.go")
+	want := []string{"InvokeExample.DivBy", "InvokeExample.UpdateValue", "InvokeExample.UpdateValues"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestSelect_ExportedOnly(t *testing.T) {
+	opts := Options{
+		Include:      []*regexp.Regexp{regexp.MustCompile(`^InvokeClass\.`), regexp.MustCompile(`half|mult`)},
+		ExportedOnly: true,
+	}
+	got := selectedNames(t, opts, "invokes/simpleCalls.go")
+	want := []string{"InvokeClass.DivBy", "InvokeClass.UpdateValue"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestLoadPackages_ExternalBodies(t *testing.T) {
+	pkgs, err := loadPackages("", "mocks/sqrt.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := pkgs[0].Prog
+	if fn := pkgs[0].Func("MockSqrt"); fn.Blocks == nil {
+		t.Error("no body for function of analyzed package")
+	}
+	if fn := prog.ImportedPackage(symbolicPackage).Func("Assume"); fn.Blocks == nil {
+		t.Error("no body for function of main module")
+	}
+	if fn := prog.ImportedPackage("math").Func("Sqrt"); fn.Blocks != nil {
+		t.Error("body for function of standard library")
+	}
+}
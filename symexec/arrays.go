@@ -0,0 +1,127 @@
+package symexec
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/aclements/go-z3/z3"
+	"golang.org/x/tools/go/ssa"
+)
+
+// isArray tells if values of type t are arrays of constant length.
+func isArray(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Array)
+	return ok
+}
+
+// zeroArray allocates backing array of zero value of array type t.
+// Elements that have no zero value of their own, i.e. arrays and structs, are allocated for every index.
+func (ctx *EncodingContext) zeroArray(t *types.Array) (z3.Uninterpreted, z3.Bool) {
+	backing, res := ctx.newBacking()
+	if ctx.zeroValue(t.Elem()) != nil {
+		// elements of new backing array are zero, see elemAddr
+		return backing, res
+	}
+	ptrT := types.NewPointer(t.Elem()).String()
+	for i := int64(0); i < t.Len(); i++ {
+		elem, valid := ctx.elemAddr(backing, ctx.FromInt(i, ctx.IntSort()).(z3.Int), ptrT, t.Elem())
+		res = res.And(valid, ctx.initZero(ptrT, elem, t.Elem()))
+	}
+	return backing, res
+}
+
+// copyArray copies elements of array of type t from backing array src to new backing array.
+// Nested arrays are copied too, other elements are copied as they are stored in memory.
+func (ctx *EncodingContext) copyArray(t *types.Array, src z3.Uninterpreted) (z3.Uninterpreted, z3.Bool) {
+	dst, res := ctx.newBacking()
+	ptrT := types.NewPointer(t.Elem()).String()
+	for i := int64(0); i < t.Len(); i++ {
+		k := ctx.FromInt(i, ctx.IntSort()).(z3.Int)
+		from, validFrom := ctx.elemAddr(src, k, ptrT, t.Elem())
+		to, validTo := ctx.elemAddr(dst, k, ptrT, t.Elem())
+		res = res.And(validFrom, validTo)
+		value := ctx.valuesMemory[ptrT].Select(from)
+		if inner, ok := t.Elem().Underlying().(*types.Array); ok {
+			backing, copied := ctx.copyArray(inner, value.(z3.Uninterpreted))
+			res = res.And(copied)
+			value = backing
+		}
+		ctx.valuesMemory[ptrT] = ctx.valuesMemory[ptrT].Store(to, value)
+	}
+	return dst, res
+}
+
+// arrayEq is elementwise equality of arrays of type t with backing arrays left and right, floats are compared like in Go.
+// Addresses of elements must be valid, it is not a part of equality.
+func (ctx *EncodingContext) arrayEq(t *types.Array, left z3.Uninterpreted, right z3.Uninterpreted) (eq z3.Bool, valid z3.Bool) {
+	eq, valid = ctx.FromBool(true), ctx.FromBool(true)
+	ptrT := types.NewPointer(t.Elem()).String()
+	for i := int64(0); i < t.Len(); i++ {
+		k := ctx.FromInt(i, ctx.IntSort()).(z3.Int)
+		l, validLeft := ctx.elemAddr(left, k, ptrT, t.Elem())
+		r, validRight := ctx.elemAddr(right, k, ptrT, t.Elem())
+		valid = valid.And(validLeft, validRight)
+		lv, rv := ctx.valuesMemory[ptrT].Select(l), ctx.valuesMemory[ptrT].Select(r)
+		if inner, ok := t.Elem().Underlying().(*types.Array); ok {
+			innerEq, innerValid := ctx.arrayEq(inner, lv.(z3.Uninterpreted), rv.(z3.Uninterpreted))
+			eq, valid = eq.And(innerEq), valid.And(innerValid)
+			continue
+		}
+		if lv, ok := lv.(z3.Float); ok {
+			eq = eq.And(lv.IEEEEq(rv.(z3.Float)))
+			continue
+		}
+		eq = eq.And(ctx.rawEq(lv, rv))
+	}
+	return eq, valid
+}
+
+// arrayValues evaluates elements of array and slice parameters and results of fn in model, they can't be read from model directly.
+// Element i of array 'a' is 'a[i]', elements of nested arrays are 'a[i][j]', see sliceValue for slices.
+func (ctx *EncodingContext) arrayValues(fn *ssa.Function, model *z3.Model) map[string]string {
+	values := make(map[string]string)
+	for _, p := range fn.Params {
+		switch a := ctx.vars[p.Name()].(type) {
+		case *FixedArray:
+			ctx.arrayValue(p.Name(), p.Type().Underlying().(*types.Array), a.addr, true, model, values)
+		case *SymArray:
+			ctx.sliceValue(p.Name(), p.Type().Underlying().(*types.Slice), a.addr, true, model, values)
+		}
+	}
+	result := ctx.vars[resultSpecialVar]
+	for i := 0; i < fn.Signature.Results().Len(); i++ {
+		r := result
+		if tuple, ok := result.(*Tuple); ok {
+			r = tuple.elems[i]
+		}
+		switch a := r.(type) {
+		case *FixedArray:
+			t := fn.Signature.Results().At(i).Type().Underlying().(*types.Array)
+			ctx.arrayValue(resultVar(fn.Signature, i), t, a.addr, false, model, values)
+		case *SymArray:
+			t := fn.Signature.Results().At(i).Type().Underlying().(*types.Slice)
+			ctx.sliceValue(resultVar(fn.Signature, i), t, a.addr, false, model, values)
+		}
+	}
+	return values
+}
+
+// arrayValue evaluates elements of array of type t with backing array in model.
+// Elements of input arrays are read from memory before any stores, elements of other arrays after all stores.
+func (ctx *EncodingContext) arrayValue(name string, t *types.Array, backing z3.Uninterpreted, input bool, model *z3.Model, values map[string]string) {
+	ptrT := types.NewPointer(t.Elem()).String()
+	memory := ctx.valuesMemory[ptrT]
+	if input {
+		memory = ctx.Const(fmt.Sprintf("$<%s>Memory", ptrT), memory.Sort()).(z3.Array)
+	}
+	for i := int64(0); i < t.Len(); i++ {
+		elem := ctx.elemOf.Apply(backing, ctx.FromInt(i, ctx.IntSort()))
+		value := memory.Select(elem)
+		elemName := fmt.Sprintf("%s[%d]", name, i)
+		if inner, ok := t.Elem().Underlying().(*types.Array); ok {
+			ctx.arrayValue(elemName, inner, value.(z3.Uninterpreted), input, model, values)
+			continue
+		}
+		values[elemName] = modelValue(value, t.Elem(), model)
+	}
+}
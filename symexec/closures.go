@@ -0,0 +1,190 @@
+package symexec
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aclements/go-z3/z3"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// isFunc tells if values of type t are function values.
+func isFunc(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Signature)
+	return ok
+}
+
+// funcID is the number of function fn, numbers start from 1 because 0 is the target of nil and input function values.
+func (ctx *EncodingContext) funcID(fn string) z3.Int {
+	i := slices.Index(ctx.funcs, fn)
+	if i < 0 {
+		ctx.funcs = append(ctx.funcs, fn)
+		i = len(ctx.funcs) - 1
+	}
+	return ctx.FromInt(int64(i+1), ctx.IntSort()).(z3.Int)
+}
+
+// target is the number of function called by function value at addr.
+// Nil calls no function, its number is 0.
+func (ctx *EncodingContext) target(addr z3.Uninterpreted) z3.Int {
+	if ctx.targetOf == nil {
+		targetOf := ctx.FuncDecl("$target", []z3.Sort{ctx.addrSort}, ctx.IntSort())
+		ctx.targetOf = &targetOf
+		ctx.asserts = append(ctx.asserts, ctx.target(ctx.nilAddr).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+	}
+	return ctx.targetOf.Apply(addr).(z3.Int)
+}
+
+// funcValue is function fn used as value, it has no bindings.
+func (ctx *EncodingContext) funcValue(fn string, t types.Type) *Closure {
+	c := &Closure{
+		addr: ctx.Const(fmt.Sprintf("$func<%s>", fn), ctx.addrSort).(z3.Uninterpreted),
+		t:    t.String(),
+		sort: ctx.addrSort,
+	}
+	ctx.asserts = append(ctx.asserts, ctx.target(c.addr).Eq(ctx.funcID(fn)))
+	return c
+}
+
+// bindings returns memory of i-th free variable of closures of function fn.
+func (ctx *EncodingContext) bindings(fn string, i int, t types.Type) string {
+	name := fmt.Sprintf("%s#%d", fn, i)
+	if _, ok := ctx.closureMemory[name]; !ok {
+		ctx.closureMemory[name] = ctx.Const(fmt.Sprintf("$<%s>Memory", name), ctx.ArraySort(ctx.addrSort, ctx.AddType(t))).(z3.Array)
+	}
+	return name
+}
+
+// unknownCall has results of call of function value at addr.
+type unknownCall struct {
+	addr    z3.Uninterpreted
+	results []SymValue
+	types   []types.Type
+}
+
+// isKnownResult tells if results of type t of input functions are rendered in tests, results of other types are zero.
+func isKnownResult(t types.Type) bool {
+	if isErrorType(t) {
+		return true
+	}
+	u, ok := t.Underlying().(*types.Basic)
+	return ok && u.Info()&types.IsComplex == 0
+}
+
+// unknownResult constrains result of input function, results of known types are limited like inputs.
+func (ctx *EncodingContext) unknownResult(v SymValue, t types.Type) z3.Bool {
+	if !isKnownResult(t) {
+		return ctx.isZero(v, t)
+	}
+	if s, ok := v.(*String); ok {
+		return s.len.LE(ctx.FromInt(maxStringLen, ctx.IntSort()).(z3.Int))
+	}
+	return ctx.FromBool(true)
+}
+
+// inputFuncs evaluates results of calls of function parameters in model in the format of initFunc.
+func (ctx *EncodingContext) inputFuncs(params []*ssa.Parameter, model *z3.Model) map[string]string {
+	funcs := make(map[string]string)
+	for _, p := range params {
+		f, ok := ctx.vars[p.Name()].(*Closure)
+		if !ok {
+			continue
+		}
+		if isNil, _ := model.Eval(f.addr.Eq(ctx.nilAddr), true).(z3.Bool).AsBool(); isNil {
+			funcs[p.Name()] = "nil"
+			continue
+		}
+		calls := 0
+		for _, call := range ctx.unknownCalls {
+			if called, _ := model.Eval(f.addr.Eq(call.addr), true).(z3.Bool).AsBool(); !called {
+				continue
+			}
+			calls++
+			for i, r := range call.results {
+				if !isKnownResult(call.types[i]) {
+					continue
+				}
+				key := fmt.Sprintf("%s()#%d", p.Name(), calls)
+				if len(call.results) > 1 {
+					key += fmt.Sprintf(".%d", i)
+				}
+				if s, ok := r.(*String); ok {
+					funcs[key] = strconv.Quote(ctx.stringValue(s, model))
+				} else {
+					funcs[key] = model.Eval(r.(z3.Value), true).String()
+				}
+			}
+		}
+		if calls > 0 {
+			funcs[fmt.Sprintf("calls(%s)", p.Name())] = strconv.Itoa(calls)
+		}
+	}
+	return funcs
+}
+
+// funcValues are functions of program that are used as values, ordered by name.
+// Functions from standard library are not included.
+func funcValues(prog *ssa.Program) []*ssa.Function {
+	standard := make(map[*ssa.Package]bool)
+	seen := make(map[*ssa.Function]struct{})
+	var res []*ssa.Function
+	add := func(fn *ssa.Function) {
+		if _, ok := seen[fn]; !ok {
+			seen[fn] = struct{}{}
+			res = append(res, fn)
+		}
+	}
+	for fn := range ssautil.AllFunctions(prog) {
+		if fn.Pkg == nil {
+			continue
+		}
+		if _, ok := standard[fn.Pkg]; !ok {
+			standard[fn.Pkg] = isStandard(prog, fn.Pkg)
+		}
+		if standard[fn.Pkg] {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if mc, ok := instr.(*ssa.MakeClosure); ok {
+					add(mc.Fn.(*ssa.Function))
+					continue
+				}
+				var ops []ssa.Value
+				if call, ok := instr.(ssa.CallInstruction); ok {
+					// callee is not a value
+					ops = call.Common().Args
+				} else {
+					for _, op := range instr.Operands(nil) {
+						ops = append(ops, *op)
+					}
+				}
+				for _, op := range ops {
+					if f, ok := op.(*ssa.Function); ok {
+						add(f)
+					}
+				}
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b *ssa.Function) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return res
+}
+
+// targets returns functions that call through function value of type t can be dispatched to.
+func targets(funcs []*ssa.Function, t types.Type) []*ssa.Function {
+	var res []*ssa.Function
+	sig := t.Underlying().(*types.Signature)
+	for _, fn := range funcs {
+		if types.Identical(fn.Signature, sig) {
+			res = append(res, fn)
+		}
+	}
+	return res
+}
@@ -0,0 +1,228 @@
+package symexec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigFilename = "gobber.yaml"
+
+// Config is read from gobber.yaml, e.g.:
+//
+//	strategy: dfs
+//	maxDepth: 200
+//	preemptions: 3
+//	timeout: 5s
+//	integers: bitvector
+//	skip: ["^debug"]
+//	mocks:
+//	  math.Sqrt: math__Sqrt
+//	output:
+//	  style: subtests
+//	packages:
+//	  example.com/cache/...:
+//	    strategy: bfs
+//	    functions:
+//	      Cache.Get:
+//	        maxDepth: 500
+//	      Cache.debugDump:
+//	        skip: true
+//
+// Settings at the top level apply to all packages, function settings override package settings.
+// Packages are import paths, 'path/...' matches all packages under path.
+type Config struct {
+	PackageConfig `yaml:",inline"`
+
+	Output   OutputConfig             `yaml:"output"`
+	Packages map[string]PackageConfig `yaml:"packages"`
+}
+
+type PackageConfig struct {
+	Strategy    Strategy      `yaml:"strategy"`
+	MaxDepth    int           `yaml:"maxDepth"`
+	Preemptions int           `yaml:"preemptions"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Integers    Integers      `yaml:"integers"`
+	// Skip are regexps of functions names ('Func' or 'Type.Method') that are not analyzed.
+	Skip []string `yaml:"skip"`
+	// Mocks replace calls to functions that can't be analyzed (e.g. 'math.Sqrt' or '(*bytes.Buffer).Len')
+	// with calls to functions in analyzed package. Without mock, 'math.Sqrt' is replaced by 'math__Sqrt' if it exists.
+	Mocks     map[string]string         `yaml:"mocks"`
+	Functions map[string]FunctionConfig `yaml:"functions"`
+}
+
+type FunctionConfig struct {
+	Strategy    Strategy      `yaml:"strategy"`
+	MaxDepth    int           `yaml:"maxDepth"`
+	Preemptions int           `yaml:"preemptions"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Integers    Integers      `yaml:"integers"`
+	Skip        bool          `yaml:"skip"`
+}
+
+type OutputConfig struct {
+	Style TestStyle `yaml:"style"`
+}
+
+// LoadConfig reads and validates config file.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	for _, pkg := range append([]PackageConfig{cfg.PackageConfig}, mapValues(cfg.Packages)...) {
+		for _, skip := range pkg.Skip {
+			if _, err := regexp.Compile(skip); err != nil {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// FindConfig looks for gobber.yaml in dir and its parents up to module root (directory with go.mod).
+// It returns empty string if there is no config.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		filename := filepath.Join(dir, ConfigFilename)
+		if _, err := os.Stat(filename); err == nil {
+			return filename, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func mapValues[K comparable, V any](m map[K]V) []V {
+	var values []V
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// packageConfigs returns configs that apply to package, from least to most specific.
+func (cfg *Config) packageConfigs(path string) []PackageConfig {
+	configs := []PackageConfig{cfg.PackageConfig}
+	// 'a/...' is less specific than 'a/b/...' that is less specific than 'a/b'
+	var matched []string
+	for pattern := range cfg.Packages {
+		if matchPackage(pattern, path) {
+			matched = append(matched, pattern)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		iWild, jWild := strings.HasSuffix(matched[i], "/..."), strings.HasSuffix(matched[j], "/...")
+		if iWild != jWild {
+			return iWild
+		}
+		return len(matched[i]) < len(matched[j])
+	})
+	for _, pattern := range matched {
+		configs = append(configs, cfg.Packages[pattern])
+	}
+	return configs
+}
+
+func matchPackage(pattern string, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return pattern == path
+}
+
+// functionSettings are settings for a single function after all configs were applied.
+type functionSettings struct {
+	strategy    Strategy
+	maxDepth    int
+	preemptions int
+	timeout     time.Duration
+	integers    Integers
+	skip        bool
+	mocks       map[string]string
+}
+
+// settings merges options and config, options given explicitly win.
+func (a *Analyzer) settings(fn *ssa.Function) functionSettings {
+	s := functionSettings{mocks: make(map[string]string)}
+	if a.config != nil && fn.Pkg != nil {
+		name := FunctionName(fn)
+		for _, pkg := range a.config.packageConfigs(fn.Pkg.Pkg.Path()) {
+			s.apply(pkg.Strategy, pkg.MaxDepth, pkg.Preemptions, pkg.Timeout, pkg.Integers)
+			for _, skip := range pkg.Skip {
+				if regexp.MustCompile(skip).MatchString(name) {
+					s.skip = true
+				}
+			}
+			for callee, mock := range pkg.Mocks {
+				s.mocks[callee] = mock
+			}
+			if f, ok := pkg.Functions[name]; ok {
+				s.apply(f.Strategy, f.MaxDepth, f.Preemptions, f.Timeout, f.Integers)
+				s.skip = s.skip || f.Skip
+			}
+		}
+	}
+	s.apply(a.opts.Strategy, a.opts.MaxDepth, a.opts.Preemptions, a.opts.SolverTimeout, a.opts.Integers)
+	if s.maxDepth <= 0 {
+		s.maxDepth = DefaultMaxDepth
+	}
+	if s.preemptions <= 0 {
+		s.preemptions = DefaultPreemptions
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultSolverTimeout
+	}
+	if s.integers == IntegersDefault {
+		s.integers = IntegersMath
+	}
+	return s
+}
+
+func (s *functionSettings) apply(strategy Strategy, maxDepth int, preemptions int, timeout time.Duration, integers Integers) {
+	if strategy != StrategyDefault {
+		s.strategy = strategy
+	}
+	if maxDepth > 0 {
+		s.maxDepth = maxDepth
+	}
+	if preemptions > 0 {
+		s.preemptions = preemptions
+	}
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+	if integers != IntegersDefault {
+		s.integers = integers
+	}
+}
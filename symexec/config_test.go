@@ -0,0 +1,181 @@
+package symexec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+strategy: dfs
+maxDepth: 200
+skip: ["^debug"]
+mocks:
+  math.Sqrt: sqrt
+output:
+  style: subtests
+packages:
+  example.com/cache/...:
+    strategy: bfs
+    timeout: 5s
+  example.com/cache/lru:
+    maxDepth: 50
+    preemptions: 4
+    integers: bitvector
+    functions:
+      Cache.Get:
+        maxDepth: 500
+      Cache.Put:
+        skip: true
+`
+
+func writeConfig(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), ConfigFilename)
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestConfig_Load(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Strategy != StrategyDFS || cfg.MaxDepth != 200 || cfg.Mocks["math.Sqrt"] != "sqrt" {
+		t.Errorf("top level settings not parsed: %+v", cfg.PackageConfig)
+	}
+	if cfg.Output.Style != StyleSubtests {
+		t.Errorf("output settings not parsed: %+v", cfg.Output)
+	}
+	if cfg.Packages["example.com/cache/..."].Timeout != 5*time.Second {
+		t.Errorf("package settings not parsed: %+v", cfg.Packages)
+	}
+	if !cfg.Packages["example.com/cache/lru"].Functions["Cache.Put"].Skip {
+		t.Errorf("function settings not parsed: %+v", cfg.Packages)
+	}
+}
+
+func TestConfig_LoadInvalid(t *testing.T) {
+	for _, content := range []string{
+		"strategy: fastest",
+		"maxDepth: 10\nmax_depth: 20",
+		"skip: ['(']",
+		"output:\n  style: table",
+	} {
+		if _, err := LoadConfig(writeConfig(t, content)); err == nil {
+			t.Errorf("no error for '%s'", content)
+		}
+	}
+}
+
+func TestConfig_Find(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FindConfig(nested); err != nil || got != "" {
+		t.Errorf("got '%s', %v; want no config", got, err)
+	}
+	want := filepath.Join(root, ConfigFilename)
+	if err := os.WriteFile(want, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FindConfig(nested); err != nil || got != want {
+		t.Errorf("got '%s', %v; want '%s'", got, err, want)
+	}
+}
+
+func TestConfig_NextToPackage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "pkg")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(root, "go.mod"):      "module example.com\n",
+		filepath.Join(dir, "pkg.go"):       "package pkg\n\nfunc Keep(a int) int { return a }\n\nfunc Debug(a int) int { return a }\n",
+		filepath.Join(dir, ConfigFilename): "skip: ['^Debug']\n",
+	}
+	for filename, content := range files {
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := NewAnalyzer(Options{Dir: root}).AnalyzePackages("./pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Config == nil || len(res[0].Functions) != 1 {
+		t.Errorf("config next to package not used: %+v", res[0])
+	}
+}
+
+func TestConfig_PackagePrecedence(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs := cfg.packageConfigs("example.com/cache/lru")
+	if len(configs) != 3 {
+		t.Fatalf("got %d configs; want 3", len(configs))
+	}
+	s := functionSettings{}
+	for _, c := range configs {
+		s.apply(c.Strategy, c.MaxDepth, c.Preemptions, c.Timeout, c.Integers)
+	}
+	if s.strategy != StrategyBFS || s.maxDepth != 50 || s.preemptions != 4 || s.timeout != 5*time.Second || s.integers != IntegersBitVector {
+		t.Errorf("got %+v", s)
+	}
+	if got := cfg.packageConfigs("example.com/cachex"); len(got) != 1 {
+		t.Errorf("got %d configs for unrelated package; want 1", len(got))
+	}
+}
+
+func TestConfig_Settings(t *testing.T) {
+	pkgs, err := loadPackages("", "numbers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := pkgs[0].Func("integerOperations")
+	cfg := &Config{PackageConfig: PackageConfig{
+		Strategy:  StrategyBFS,
+		MaxDepth:  20,
+		Functions: map[string]FunctionConfig{"integerOperations": {MaxDepth: 30}},
+	}}
+
+	s := NewAnalyzer(Options{Config: cfg}).settings(fn)
+	if s.strategy != StrategyBFS || s.maxDepth != 30 || s.timeout != DefaultSolverTimeout {
+		t.Errorf("config: got %+v", s)
+	}
+	s = NewAnalyzer(Options{Config: cfg, MaxDepth: 40}).settings(fn)
+	if s.maxDepth != 40 {
+		t.Errorf("options: got max depth %d; want 40", s.maxDepth)
+	}
+	s = NewAnalyzer(Options{}).settings(fn)
+	if s.strategy != StrategyDefault || s.maxDepth != DefaultMaxDepth || s.preemptions != DefaultPreemptions || s.integers != IntegersMath {
+		t.Errorf("defaults: got %+v", s)
+	}
+}
+
+func TestConfig_Mock(t *testing.T) {
+	pkgs, err := loadPackages("", "mocks/sqrt.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	sqrt := pkg.Prog.ImportedPackage("math").Func("Sqrt")
+	a := NewAnalyzer(Options{})
+	a.current = a.settings(pkg.Func("MockSqrt"))
+	if got := a.mock(sqrt, pkg.Func("MockSqrt"), pkg); got != pkg.Func("math__Sqrt") {
+		t.Errorf("got mock %v; want math__Sqrt", got)
+	}
+	if got := a.mock(sqrt, pkg.Func("math__Sqrt"), pkg); got != nil {
+		t.Errorf("got mock %v inside mock; want none", got)
+	}
+}
@@ -5,18 +5,53 @@ import (
 	"go/types"
 	"math"
 	"math/big"
+	"slices"
 
 	"github.com/aclements/go-z3/z3"
+	"golang.org/x/tools/go/ssa"
 )
 
 const (
 	intSize     = 64
 	floatSize   = 64
+	float32Size = 32
 	complexSize = 64
 
 	resultSpecialVar = "$result"
 )
 
+// tupleElem is the name of variable for element of tuple, e.g. '$result.1'.
+func tupleElem(name string, i int) string {
+	return fmt.Sprintf("%s.%d", name, i)
+}
+
+// isErrorType tells if t is 'error'. Errors are encoded as booleans that are true if error is not nil.
+func isErrorType(t types.Type) bool {
+	// t can be NamedStruct that types.Identical doesn't know about
+	named, ok := t.(*types.Named)
+	return ok && named.Obj() == types.Universe.Lookup("error")
+}
+
+// resultVar is the name of variable for i-th result of function.
+func resultVar(sig *types.Signature, i int) string {
+	if sig.Results().Len() == 1 {
+		return resultSpecialVar
+	}
+	return tupleElem(resultSpecialVar, i)
+}
+
+// resultType is the type of '$result' variable, nil if function has no results.
+func resultType(sig *types.Signature) types.Type {
+	switch sig.Results().Len() {
+	case 0:
+		return nil
+	case 1:
+		return sig.Results().At(0).Type()
+	default:
+		return sig.Results()
+	}
+}
+
 type EncodingContext struct {
 	*z3.Context
 
@@ -28,16 +63,50 @@ type EncodingContext struct {
 	varsUsed map[string]struct{}
 	varCount map[string]int
 
-	fieldsMemory      map[string][]z3.Array
-	valuesMemory      map[string]z3.Array
-	arrayValuesMemory map[string]z3.Array
-	arrayLenMemory    map[string]z3.Array
+	fieldsMemory map[string][]z3.Array
+	valuesMemory map[string]z3.Array
+	// slices are headers of backing array, offset, length and capacity, elements are addressed by elemAddr
+	arrayBackingMemory map[string]z3.Array
+	arrayOffsetMemory  map[string]z3.Array
+	arrayLenMemory     map[string]z3.Array
+	arrayCapMemory     map[string]z3.Array
+	// maps are arrays of values and presence of keys, length is the number of present keys
+	mapValuesMemory  map[string]z3.Array
+	mapPresentMemory map[string]z3.Array
+	mapLenMemory     map[string]z3.Array
+	// mapKeys are keys used with maps of every type
+	mapKeys map[string][]z3.Value
+
+	// bitVectors is set if integers are bit-vectors of their width, see IntegersBitVector.
+	bitVectors bool
 
 	floatSort   z3.Sort
+	float32Sort z3.Sort
 	complexSort z3.Sort
 	stringSort  z3.Sort
 
 	addrSort z3.Sort
+
+	// heap maps address to the number of allocation that created it, see heapOf.
+	heap    *z3.FuncDecl
+	allocs  int
+	nilAddr z3.Uninterpreted
+
+	// elemOf maps backing array and index to the address of element, indexOf is the index of element, see elemAddr.
+	elemOf  z3.FuncDecl
+	indexOf z3.FuncDecl
+
+	// typeOf maps address of interface box to the number of its dynamic type, see typeID and dynamicType.
+	typeOf       *z3.FuncDecl
+	dynamicTypes []types.Type
+
+	// targetOf maps address of function value to the number of function it calls, see funcID and target.
+	targetOf *z3.FuncDecl
+	funcs    []string
+	// closureMemory has bound free variables of closures of every function
+	closureMemory map[string]z3.Array
+	// unknownCalls are calls of input function values in order of encoding, see UnknownCall.
+	unknownCalls []unknownCall
 }
 
 type NamedStruct struct {
@@ -46,39 +115,53 @@ type NamedStruct struct {
 }
 
 func (ctx *EncodingContext) AddType(t types.Type) z3.Sort {
+	t = types.Unalias(t)
 	if _, ok := ctx.rawTypes[t.String()]; !ok {
+		if isErrorType(t) {
+			ctx.rawTypes[t.String()] = ctx.BoolSort()
+			return ctx.rawTypes[t.String()]
+		}
 		switch t := t.(type) {
 		case *types.Basic:
 			switch t.Kind() {
 			case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
-				ctx.rawTypes[t.String()] = ctx.IntSort()
+				ctx.rawTypes[t.String()] = ctx.intSort(t)
 			case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
-				ctx.rawTypes[t.String()] = ctx.IntSort()
+				ctx.rawTypes[t.String()] = ctx.intSort(t)
 			case types.Bool:
 				ctx.rawTypes[t.String()] = ctx.BoolSort()
 			case types.Float64:
 				ctx.rawTypes[t.String()] = ctx.floatSort
+			case types.Float32:
+				ctx.rawTypes[t.String()] = ctx.float32Sort
 			case types.Complex128:
 				ctx.rawTypes[t.String()] = ctx.addrSort // TODO: complex number representation as z3.Sort
 			case types.String:
-				ctx.rawTypes[t.String()] = ctx.addrSort // TODO: string representation as z3.Sort
+				// strings in memory are bit-vectors, see rawString
+				ctx.rawTypes[t.String()] = ctx.BVSort(stringBits)
 			default:
-				panic(fmt.Sprintf("unknown basic type '%s'", t))
+				panic(unsupported("basic type "+t.Name(), ""))
 			}
 		case *types.Pointer:
 			elemT := ctx.AddType(t.Elem())
 			ctx.valuesMemory[t.String()] = ctx.Const(fmt.Sprintf("$<%s>Memory", t), ctx.ArraySort(ctx.addrSort, elemT)).(z3.Array)
 			ctx.rawTypes[t.String()] = ctx.addrSort
 		case *types.Slice:
-			elemT := ctx.AddType(types.NewPointer(t.Elem()))
-			ctx.arrayValuesMemory[t.String()] = ctx.Const(
-				fmt.Sprintf("$<%s>ValuesMemory", t),
-				ctx.ArraySort(ctx.addrSort, ctx.ArraySort(ctx.IntSort(), elemT)),
-			).(z3.Array)
-			ctx.arrayLenMemory[t.String()] = ctx.Const(
-				fmt.Sprintf("$<%s>LenMemory", t),
-				ctx.ArraySort(ctx.addrSort, ctx.IntSort()),
-			).(z3.Array)
+			ctx.AddType(types.NewPointer(t.Elem()))
+			ctx.rawTypes[t.String()] = ctx.addrSort
+			backing, offsets, lens, caps := ctx.initialSliceMemory(t)
+			ctx.arrayBackingMemory[t.String()] = backing
+			ctx.arrayOffsetMemory[t.String()] = offsets
+			ctx.arrayLenMemory[t.String()] = lens
+			ctx.arrayCapMemory[t.String()] = caps
+			// nil slice is empty
+			ctx.asserts = append(ctx.asserts,
+				lens.Select(ctx.nilAddr).(z3.Int).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)),
+				caps.Select(ctx.nilAddr).(z3.Int).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)),
+			)
+		case *types.Array:
+			// arrays are backing arrays of constant length
+			ctx.AddType(types.NewPointer(t.Elem()))
 			ctx.rawTypes[t.String()] = ctx.addrSort
 		case *types.Struct:
 			var fields []z3.Array
@@ -107,76 +190,109 @@ func (ctx *EncodingContext) AddType(t types.Type) z3.Sort {
 			ctx.fieldsMemory[t.Name] = fields
 			ctx.rawTypes[t.Name] = ctx.addrSort
 		case *types.Named:
-			ctx.AddType(NamedStruct{Struct: t.Underlying().(*types.Struct), Name: t.String()})
+			switch u := t.Underlying().(type) {
+			case *types.Struct:
+				ctx.AddType(NamedStruct{Struct: u, Name: t.String()})
+			case *types.Interface, *types.Signature:
+				ctx.rawTypes[t.String()] = ctx.addrSort
+			case *types.Array:
+				ctx.AddType(u)
+				ctx.rawTypes[t.String()] = ctx.addrSort
+			case *types.Basic:
+				// named basic types are represented like their underlying types
+				ctx.rawTypes[t.String()] = ctx.AddType(u)
+			default:
+				panic(unsupported(fmt.Sprintf("%T type", t.Underlying()), "%s", t))
+			}
+		case *types.Interface, *types.Signature:
+			ctx.rawTypes[t.String()] = ctx.addrSort
+		case *types.Chan:
+			// channels are numbers, see MakeChan
+			ctx.AddType(t.Elem())
+			ctx.rawTypes[t.String()] = ctx.IntSort()
+		case *types.Tuple:
+			for i := 0; i < t.Len(); i++ {
+				if !isUnused(t.At(i).Type()) {
+					ctx.AddType(t.At(i).Type())
+				}
+			}
+		case *types.Map:
+			keyT := ctx.AddType(t.Key())
+			ctx.AddType(t.Elem())
+			ctx.rawTypes[t.String()] = ctx.addrSort
+			values, present, lens := ctx.initialMapMemory(t)
+			ctx.mapValuesMemory[t.String()] = values
+			ctx.mapPresentMemory[t.String()] = present
+			ctx.mapLenMemory[t.String()] = lens
+			// nil map is empty
+			ctx.asserts = append(ctx.asserts,
+				lens.Select(ctx.nilAddr).(z3.Int).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)),
+				present.Select(ctx.nilAddr).(z3.Array).Eq(ctx.ConstArray(keyT, ctx.FromBool(false))),
+			)
 		default:
-			panic(fmt.Sprintf("unknown type '%s'", t))
+			panic(unsupported(fmt.Sprintf("%T type", t), "%s", t))
 		}
 	}
 	return ctx.rawTypes[t.String()]
 }
 
 func (ctx *EncodingContext) AddVar(name string, z3name string, t types.Type) {
+	if isErrorType(t) {
+		ctx.vars[name] = ctx.BoolConst(z3name)
+		return
+	}
+	t = types.Unalias(t)
+	if isInterface(t) {
+		ctx.vars[name] = &Interface{
+			addr: ctx.Const(z3name, ctx.addrSort).(z3.Uninterpreted),
+			t:    t.String(),
+			sort: ctx.addrSort,
+		}
+		return
+	}
+	if isFunc(t) {
+		ctx.vars[name] = &Closure{
+			addr: ctx.Const(z3name, ctx.addrSort).(z3.Uninterpreted),
+			t:    t.String(),
+			sort: ctx.addrSort,
+		}
+		return
+	}
+	if isArray(t) {
+		ctx.vars[name] = &FixedArray{
+			addr: ctx.Const(z3name, ctx.addrSort).(z3.Uninterpreted),
+			t:    t.String(),
+			sort: ctx.addrSort,
+		}
+		return
+	}
+	if u, ok := t.Underlying().(*types.Basic); ok {
+		t = u
+	}
 	switch t := t.(type) {
 	case *types.Basic:
 		switch t.Kind() {
-		case types.Int:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxInt, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(math.MinInt, ctx.IntSort()).(z3.Int)))
-		case types.Int8:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxInt8, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(math.MinInt8, ctx.IntSort()).(z3.Int)))
-		case types.Int16:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxInt16, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(math.MinInt16, ctx.IntSort()).(z3.Int)))
-		case types.Int32:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxInt32, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(math.MinInt32, ctx.IntSort()).(z3.Int)))
-		case types.Int64:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxInt64, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(math.MinInt64, ctx.IntSort()).(z3.Int)))
-		case types.Uint:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromBigInt(new(big.Int).SetUint64(math.MaxUint), ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
-		case types.Uint8:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxUint8, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
-		case types.Uint16:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxUint16, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
-		case types.Uint32:
-			i := ctx.IntConst(z3name)
-			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromInt(math.MaxUint32, ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
-		case types.Uint64:
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			if ctx.bitVectors {
+				// bit-vectors are always in range
+				ctx.vars[name] = ctx.Const(z3name, ctx.intSort(t))
+				break
+			}
 			i := ctx.IntConst(z3name)
 			ctx.vars[name] = i
-			ctx.asserts = append(ctx.asserts, i.LE(ctx.FromBigInt(new(big.Int).SetUint64(math.MaxUint64), ctx.IntSort()).(z3.Int)))
-			ctx.asserts = append(ctx.asserts, i.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+			ctx.asserts = append(ctx.asserts, ctx.inRange(i, t))
 		case types.Bool:
 			ctx.vars[name] = ctx.BoolConst(z3name)
 		case types.Float64:
 			ctx.vars[name] = ctx.Const(z3name, ctx.floatSort)
+		case types.Float32:
+			ctx.vars[name] = ctx.Const(z3name, ctx.float32Sort)
 		case types.Complex128:
 			ctx.vars[name] = ctx.ComplexConst(z3name)
 		case types.String:
-			ctx.vars[name] = ctx.StringConst(z3name)
+			s := ctx.StringConst(z3name)
+			ctx.vars[name] = s
+			ctx.asserts = append(ctx.asserts, s.len.GE(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
 		}
 	case *types.Pointer:
 		ctx.vars[name] = ctx.PointerConst(z3name, t.String(), t.Elem().String())
@@ -184,9 +300,353 @@ func (ctx *EncodingContext) AddVar(name string, z3name string, t types.Type) {
 		ctx.vars[name] = ctx.SymArrayConst(z3name, t.String())
 	case *types.Struct:
 		ctx.vars[name] = ctx.SymStructConst(z3name, t.String())
+	case *types.Named:
+		if _, ok := t.Underlying().(*types.Struct); !ok {
+			panic(unsupported(fmt.Sprintf("%T type", t.Underlying()), "variable '%s' of type '%s'", name, t))
+		}
+		ctx.vars[name] = ctx.SymStructConst(z3name, t.String())
+	case *types.Chan:
+		ctx.vars[name] = ctx.IntConst(z3name)
+	case *types.Map:
+		ctx.vars[name] = &SymMap{
+			addr: ctx.Const(z3name, ctx.addrSort).(z3.Uninterpreted),
+			t:    t.String(),
+			sort: ctx.addrSort,
+		}
+	case *types.Tuple:
+		tuple := &Tuple{}
+		for i := 0; i < t.Len(); i++ {
+			if isUnused(t.At(i).Type()) {
+				tuple.elems = append(tuple.elems, nil)
+				continue
+			}
+			ctx.AddVar(tupleElem(name, i), tupleElem(z3name, i), t.At(i).Type())
+			tuple.elems = append(tuple.elems, ctx.vars[tupleElem(name, i)])
+		}
+		ctx.vars[name] = tuple
 	default:
-		panic(fmt.Sprintf("variable '%s' of unknown type '%s'", name, t))
+		panic(unsupported(fmt.Sprintf("%T type", t), "variable '%s' of type '%s'", name, t))
+	}
+}
+
+// inRange tells if integer v fits in type t and if string in memory v is valid, it is true for values of other types.
+func (ctx *EncodingContext) inRange(v z3.Value, t types.Type) z3.Bool {
+	u, ok := t.Underlying().(*types.Basic)
+	if ok && u.Kind() == types.String {
+		return ctx.isRawString(v.(z3.BV))
+	}
+	if _, isInt := v.(z3.Int); !ok || !isInt {
+		return ctx.FromBool(true)
+	}
+	var low, high *big.Int
+	switch u.Kind() {
+	case types.Int, types.Int64:
+		low, high = big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	case types.Int8:
+		low, high = big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)
+	case types.Int16:
+		low, high = big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)
+	case types.Int32:
+		low, high = big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)
+	case types.Uint, types.Uint64:
+		low, high = big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)
+	case types.Uint8:
+		low, high = big.NewInt(0), big.NewInt(math.MaxUint8)
+	case types.Uint16:
+		low, high = big.NewInt(0), big.NewInt(math.MaxUint16)
+	case types.Uint32:
+		low, high = big.NewInt(0), big.NewInt(math.MaxUint32)
+	default:
+		return ctx.FromBool(true)
+	}
+	i := v.(z3.Int)
+	return i.GE(ctx.FromBigInt(low, ctx.IntSort()).(z3.Int)).And(i.LE(ctx.FromBigInt(high, ctx.IntSort()).(z3.Int)))
+}
+
+// eq is equality of values of the same type.
+func (ctx *EncodingContext) eq(left SymValue, right SymValue) z3.Bool {
+	switch left := left.(type) {
+	case z3.Int:
+		return left.Eq(right.(z3.Int))
+	case z3.BV:
+		return left.Eq(right.(z3.BV))
+	case z3.Bool:
+		return left.Eq(right.(z3.Bool))
+	case z3.Float:
+		return left.Eq(right.(z3.Float))
+	case *Complex:
+		right := right.(*Complex)
+		return left.real.Eq(right.real).And(left.imag.Eq(right.imag))
+	case *String:
+		// left is the variable being defined, so its length is bounded by right
+		right := right.(*String)
+		left.max = right.max
+		return ctx.stringEq(left, right, right.max)
+	case *SymArray:
+		return left.addr.Eq(right.(*SymArray).addr)
+	case *FixedArray:
+		return left.addr.Eq(right.(*FixedArray).addr)
+	case *SymStruct:
+		return left.addr.Eq(right.(*SymStruct).addr)
+	case *Pointer:
+		return left.addr.Eq(right.(*Pointer).addr)
+	case *SymMap:
+		return left.addr.Eq(right.(*SymMap).addr)
+	case *Interface:
+		return left.addr.Eq(right.(*Interface).addr)
+	case *Closure:
+		return left.addr.Eq(right.(*Closure).addr)
+	case *Tuple:
+		right := right.(*Tuple)
+		res := ctx.FromBool(true)
+		for i := range left.elems {
+			res = res.And(ctx.eq(left.elems[i], right.elems[i]))
+		}
+		return res
+	}
+	panic(unsupported("return type", "sort '%s'", left.Sort()))
+}
+
+// initHeap must be called before encoding.
+// $heap, $type and $target are declared with their assertions about nil when they are first used,
+// formulas that don't need them are solved faster without them.
+func (ctx *EncodingContext) initHeap() {
+	ctx.nilAddr = ctx.Const("$nil", ctx.addrSort).(z3.Uninterpreted)
+	ctx.elemOf = ctx.FuncDecl("$elem", []z3.Sort{ctx.addrSort, ctx.IntSort()}, ctx.addrSort)
+	ctx.indexOf = ctx.FuncDecl("$index", []z3.Sort{ctx.addrSort}, ctx.IntSort())
+}
+
+// heapOf is the number of allocation that created object at addr.
+// Objects allocated by analyzed code have positive allocation numbers, so they are distinct from each other.
+// Inputs and nil have non-positive numbers, fields of such objects too.
+func (ctx *EncodingContext) heapOf(addr z3.Uninterpreted) z3.Int {
+	if ctx.heap == nil {
+		heap := ctx.FuncDecl("$heap", []z3.Sort{ctx.addrSort}, ctx.IntSort())
+		ctx.heap = &heap
+		ctx.asserts = append(ctx.asserts, ctx.isInput(ctx.nilAddr))
+	}
+	return ctx.heap.Apply(addr).(z3.Int)
+}
+
+// isInput tells if address was not allocated by analyzed code.
+func (ctx *EncodingContext) isInput(addr z3.Uninterpreted) z3.Bool {
+	return ctx.heapOf(addr).LE(ctx.FromInt(0, ctx.IntSort()).(z3.Int))
+}
+
+// allocate marks address as allocated by analyzed code.
+func (ctx *EncodingContext) allocate(addr z3.Uninterpreted) z3.Bool {
+	ctx.allocs++
+	return ctx.heapOf(addr).Eq(ctx.FromInt(int64(ctx.allocs), ctx.IntSort()).(z3.Int))
+}
+
+// zeroValue is the value of variables of type t that were not initialized.
+// It is nil for structs, they are allocated by initZero.
+func (ctx *EncodingContext) zeroValue(t types.Type) z3.Value {
+	if isErrorType(t) {
+		return ctx.FromBool(false)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return ctx.FromInt(0, ctx.intSort(u))
+		case types.Bool:
+			return ctx.FromBool(false)
+		case types.Float64:
+			return ctx.FloatZero(ctx.floatSort, false)
+		case types.Float32:
+			return ctx.FloatZero(ctx.float32Sort, false)
+		case types.String:
+			return ctx.FromInt(0, ctx.BVSort(stringBits))
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Signature:
+		return ctx.nilAddr
+	case *types.Chan:
+		return ctx.FromInt(0, ctx.IntSort())
+	}
+	return nil
+}
+
+// isZero tells if v is the zero value of type t.
+func (ctx *EncodingContext) isZero(v SymValue, t types.Type) z3.Bool {
+	if s, ok := v.(*String); ok {
+		return ctx.eq(s, ctx.FromString(""))
+	}
+	zero := ctx.zeroValue(t)
+	if zero == nil {
+		panic(unsupported("zero value", "of type '%s'", t))
+	}
+	return ctx.rawEq(ctx.rawValue(v), zero)
+}
+
+// initZero allocates zero value of type t at addr of pointer type ptrT.
+func (ctx *EncodingContext) initZero(ptrT string, addr z3.Uninterpreted, t types.Type) z3.Bool {
+	if zero := ctx.zeroValue(t); zero != nil {
+		ctx.valuesMemory[ptrT] = ctx.valuesMemory[ptrT].Store(addr, zero)
+		return ctx.FromBool(true)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		str, res := ctx.zeroStruct(t, u)
+		ctx.valuesMemory[ptrT] = ctx.valuesMemory[ptrT].Store(addr, str)
+		return res
+	case *types.Array:
+		backing, allocated := ctx.zeroArray(u)
+		ctx.valuesMemory[ptrT] = ctx.valuesMemory[ptrT].Store(addr, backing)
+		return allocated
+	}
+	panic(unsupported("allocation", "of type '%s'", t))
+}
+
+// zeroStruct allocates zero value of struct type t with underlying type u, fields are allocated too.
+func (ctx *EncodingContext) zeroStruct(t types.Type, u *types.Struct) (z3.Uninterpreted, z3.Bool) {
+	str := ctx.Const(fmt.Sprintf("$alloc%d", ctx.allocs+1), ctx.addrSort).(z3.Uninterpreted)
+	res := ctx.allocate(str)
+	fields := ctx.fieldsMemory[t.String()]
+	for i := 0; i < u.NumFields(); i++ {
+		fieldT := u.Field(i).Type()
+		field := ctx.Const(fmt.Sprintf("$alloc%d", ctx.allocs+1), ctx.addrSort).(z3.Uninterpreted)
+		res = res.And(ctx.allocate(field))
+		fields[i] = fields[i].Store(str, field)
+		res = res.And(ctx.initZero(types.NewPointer(fieldT).String(), field, fieldT))
+	}
+	return str, res
+}
+
+// rawValue is the value of v as stored in memory, objects are stored by address.
+func (ctx *EncodingContext) rawValue(v SymValue) z3.Value {
+	switch v := v.(type) {
+	case z3.Int:
+		return v
+	case z3.BV:
+		return v
+	case z3.Bool:
+		return v
+	case z3.Float:
+		return v
+	case *Pointer:
+		return v.addr
+	case *SymArray:
+		return v.addr
+	case *FixedArray:
+		return v.addr
+	case *SymStruct:
+		return v.addr
+	case *SymMap:
+		return v.addr
+	case *Interface:
+		return v.addr
+	case *Closure:
+		return v.addr
+	case *String:
+		return ctx.rawString(v)
+	}
+	panic(unsupported("value in memory", "of sort '%s'", v.Sort()))
+}
+
+// rawEq is equality of values returned by rawValue.
+func (ctx *EncodingContext) rawEq(left z3.Value, right z3.Value) z3.Bool {
+	switch left := left.(type) {
+	case z3.Int:
+		return left.Eq(right.(z3.Int))
+	case z3.BV:
+		return left.Eq(right.(z3.BV))
+	case z3.Bool:
+		return left.Eq(right.(z3.Bool))
+	case z3.Float:
+		return left.Eq(right.(z3.Float))
+	case z3.Uninterpreted:
+		return left.Eq(right.(z3.Uninterpreted))
 	}
+	panic(fmt.Sprintf("unexpected value of sort '%s'", left.Sort()))
+}
+
+// mapKey records key used with map m, keys are needed to build input maps, see constrainInputMaps.
+func (ctx *EncodingContext) mapKey(m *SymMap, key SymValue) z3.Value {
+	k := ctx.rawValue(key)
+	ctx.mapKeys[m.t] = append(ctx.mapKeys[m.t], k)
+	return k
+}
+
+// initialMapMemory is memory of maps of type t before any updates, i.e. memory of input maps.
+func (ctx *EncodingContext) initialMapMemory(t *types.Map) (values z3.Array, present z3.Array, lens z3.Array) {
+	keyT := ctx.rawTypes[t.Key().String()]
+	valueT := ctx.rawTypes[t.Elem().String()]
+	values = ctx.Const(
+		fmt.Sprintf("$<%s>ValuesMemory", t),
+		ctx.ArraySort(ctx.addrSort, ctx.ArraySort(keyT, valueT)),
+	).(z3.Array)
+	present = ctx.Const(
+		fmt.Sprintf("$<%s>PresentMemory", t),
+		ctx.ArraySort(ctx.addrSort, ctx.ArraySort(keyT, ctx.BoolSort())),
+	).(z3.Array)
+	lens = ctx.Const(
+		fmt.Sprintf("$<%s>LenMemory", t),
+		ctx.ArraySort(ctx.addrSort, ctx.IntSort()),
+	).(z3.Array)
+	return values, present, lens
+}
+
+// constrainInputMaps makes length of input maps equal to the number of keys that are present.
+// Only keys used by analyzed code are counted, other keys are not in generated map literals anyway.
+// It must be called after encoding, when all keys are known.
+func (ctx *EncodingContext) constrainInputMaps(params []*ssa.Parameter) {
+	for _, p := range params {
+		t, ok := p.Type().(*types.Map)
+		m, used := ctx.vars[p.Name()].(*SymMap)
+		if !ok || !used {
+			continue
+		}
+		values, present, lens := ctx.initialMapMemory(t)
+		keys := ctx.mapKeys[t.String()]
+		count := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+		for i, key := range keys {
+			// duplicate keys are counted once
+			isNew := present.Select(m.addr).(z3.Array).Select(key).(z3.Bool)
+			for _, prev := range keys[:i] {
+				isNew = isNew.And(ctx.rawEq(key, prev).Not())
+			}
+			count = count.Add(isNew.IfThenElse(ctx.FromInt(1, ctx.IntSort()), ctx.FromInt(0, ctx.IntSort())).(z3.Int))
+		}
+		ctx.asserts = append(ctx.asserts, lens.Select(m.addr).(z3.Int).Eq(count))
+		// values of input maps are valid like other inputs
+		for _, key := range keys {
+			ctx.asserts = append(ctx.asserts, ctx.inRange(values.Select(m.addr).(z3.Array).Select(key), t.Elem()))
+		}
+	}
+}
+
+// inputMaps evaluates contents of input maps in model.
+func (ctx *EncodingContext) inputMaps(params []*ssa.Parameter, model *z3.Model) map[string]inputMap {
+	maps := make(map[string]inputMap)
+	for _, p := range params {
+		t, ok := p.Type().(*types.Map)
+		m, used := ctx.vars[p.Name()].(*SymMap)
+		if !ok || !used {
+			continue
+		}
+		if isNil, _ := model.Eval(m.addr.Eq(ctx.nilAddr), true).(z3.Bool).AsBool(); isNil {
+			maps[p.Name()] = inputMap{isNil: true}
+			continue
+		}
+		values, present, _ := ctx.initialMapMemory(t)
+		entries := []mapEntry{}
+		for _, key := range ctx.mapKeys[t.String()] {
+			isPresent, _ := model.Eval(present.Select(m.addr).(z3.Array).Select(key), true).(z3.Bool).AsBool()
+			if !isPresent {
+				continue
+			}
+			entry := mapEntry{
+				key:   modelValue(key, t.Key(), model),
+				value: modelValue(values.Select(m.addr).(z3.Array).Select(key), t.Elem(), model),
+			}
+			if !slices.ContainsFunc(entries, func(e mapEntry) bool { return e.key == entry.key }) {
+				entries = append(entries, entry)
+			}
+		}
+		maps[p.Name()] = inputMap{entries: entries}
+	}
+	return maps
 }
 
 func (ctx *EncodingContext) ComplexConst(name string) *Complex {
@@ -197,12 +657,6 @@ func (ctx *EncodingContext) ComplexConst(name string) *Complex {
 	}
 }
 
-func (ctx *EncodingContext) StringConst(name string) *String {
-	return &String{
-		sort: ctx.stringSort,
-	}
-}
-
 func (ctx *EncodingContext) SymArrayConst(name string, t string) *SymArray {
 	return &SymArray{
 		addr: ctx.Const(name, ctx.addrSort).(z3.Uninterpreted),
@@ -0,0 +1,220 @@
+package symexec
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// UnreachedReason tells why block or branch was not covered by any testcase.
+// Reasons are ordered, if there are several the greatest one is reported.
+type UnreachedReason int
+
+const (
+	ReasonNone UnreachedReason = iota
+	// ReasonInfeasible means that path condition is unsatisfiable, there is no input to reach block.
+	ReasonInfeasible
+	// ReasonDepthLimit means that path was dropped after MaxDepth blocks.
+	ReasonDepthLimit
+	// ReasonSolverTimeout means that solver could not decide if path is feasible.
+	ReasonSolverTimeout
+	// ReasonUnsupported means that analysis was aborted.
+	ReasonUnsupported
+)
+
+func (r UnreachedReason) String() string {
+	switch r {
+	case ReasonNone:
+		return ""
+	case ReasonInfeasible:
+		return "infeasible"
+	case ReasonDepthLimit:
+		return "depth limit"
+	case ReasonSolverTimeout:
+		return "solver timeout"
+	case ReasonUnsupported:
+		return "unsupported"
+	default:
+		return fmt.Sprintf("UnreachedReason(%d)", int(r))
+	}
+}
+
+func (r UnreachedReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Coverage of SSA blocks and branches of analyzed function by generated testcases.
+type Coverage struct {
+	Blocks []BlockCoverage `json:"blocks"`
+	// Branches are edges from blocks with several successors ('if' instructions).
+	Branches []BranchCoverage `json:"branches"`
+}
+
+type BlockCoverage struct {
+	Index int `json:"index"`
+	// Lines are source lines of block instructions, it can be empty for synthetic blocks.
+	Lines   []int           `json:"lines"`
+	Covered bool            `json:"covered"`
+	Reason  UnreachedReason `json:"reason,omitempty"`
+}
+
+type BranchCoverage struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Covered bool            `json:"covered"`
+	Reason  UnreachedReason `json:"reason,omitempty"`
+}
+
+type edge struct {
+	from int
+	to   int
+}
+
+// unreachedLog collects reasons why exploration of analyzed function stopped.
+// Blocks that are not reached without any recorded reason are infeasible, exploration is exhaustive otherwise.
+type unreachedLog struct {
+	blocks map[int]UnreachedReason
+	edges  map[edge]UnreachedReason
+}
+
+func newUnreachedLog() *unreachedLog {
+	return &unreachedLog{
+		blocks: make(map[int]UnreachedReason),
+		edges:  make(map[edge]UnreachedReason),
+	}
+}
+
+func (l *unreachedLog) block(index int, reason UnreachedReason) {
+	l.blocks[index] = max(l.blocks[index], reason)
+}
+
+func (l *unreachedLog) edge(from int, to int, reason UnreachedReason) {
+	e := edge{from, to}
+	l.edges[e] = max(l.edges[e], reason)
+}
+
+// newCoverage aggregates paths of testcases, aborted is set if analysis of function failed.
+func newCoverage(fn *ssa.Function, testcases []Testcase, log *unreachedLog, aborted bool) *Coverage {
+	covered := make(map[int]bool)
+	coveredEdges := make(map[edge]bool)
+	for _, tc := range testcases {
+		for i, b := range tc.path {
+			covered[b] = true
+			if i > 0 {
+				coveredEdges[edge{tc.path[i-1], b}] = true
+			}
+		}
+	}
+
+	floor := ReasonInfeasible
+	if aborted {
+		floor = ReasonUnsupported
+	}
+	edgeReason := func(e edge, reasons []UnreachedReason) UnreachedReason {
+		if !covered[e.from] {
+			return reasons[e.from]
+		}
+		return max(floor, log.edges[e], log.blocks[e.to])
+	}
+	// reasons are propagated to successors until nothing changes, they only grow so it terminates
+	reasons := make([]UnreachedReason, len(fn.Blocks))
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			if covered[b.Index] {
+				continue
+			}
+			reason := max(floor, log.blocks[b.Index])
+			for _, pred := range b.Preds {
+				reason = max(reason, edgeReason(edge{pred.Index, b.Index}, reasons))
+			}
+			if reason != reasons[b.Index] {
+				reasons[b.Index] = reason
+				changed = true
+			}
+		}
+	}
+
+	c := &Coverage{Blocks: []BlockCoverage{}, Branches: []BranchCoverage{}}
+	for _, b := range fn.Blocks {
+		bc := BlockCoverage{Index: b.Index, Lines: blockLines(fn, b), Covered: covered[b.Index]}
+		if !bc.Covered {
+			bc.Reason = reasons[b.Index]
+		}
+		c.Blocks = append(c.Blocks, bc)
+		if len(b.Succs) < 2 {
+			continue
+		}
+		for _, succ := range b.Succs {
+			e := edge{b.Index, succ.Index}
+			bc := BranchCoverage{From: e.from, To: e.to, Covered: coveredEdges[e]}
+			if !bc.Covered {
+				bc.Reason = edgeReason(e, reasons)
+			}
+			c.Branches = append(c.Branches, bc)
+		}
+	}
+	return c
+}
+
+func blockLines(fn *ssa.Function, b *ssa.BasicBlock) []int {
+	lines := []int{}
+	for _, instr := range b.Instrs {
+		if !instr.Pos().IsValid() {
+			continue
+		}
+		line := fn.Prog.Fset.Position(instr.Pos()).Line
+		if !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// CoveredBlocks returns number of covered and all blocks.
+func (c *Coverage) CoveredBlocks() (int, int) {
+	covered := 0
+	for _, b := range c.Blocks {
+		if b.Covered {
+			covered++
+		}
+	}
+	return covered, len(c.Blocks)
+}
+
+// CoveredBranches returns number of covered and all branches.
+func (c *Coverage) CoveredBranches() (int, int) {
+	covered := 0
+	for _, b := range c.Branches {
+		if b.Covered {
+			covered++
+		}
+	}
+	return covered, len(c.Branches)
+}
+
+// UncoveredLines maps source lines that belong only to uncovered blocks to reason.
+func (c *Coverage) UncoveredLines() map[int]UnreachedReason {
+	coveredLines := make(map[int]bool)
+	for _, b := range c.Blocks {
+		if b.Covered {
+			for _, line := range b.Lines {
+				coveredLines[line] = true
+			}
+		}
+	}
+	lines := make(map[int]UnreachedReason)
+	for _, b := range c.Blocks {
+		if b.Covered {
+			continue
+		}
+		for _, line := range b.Lines {
+			if !coveredLines[line] {
+				lines[line] = max(lines[line], b.Reason)
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,78 @@
+package symexec
+
+import (
+	"maps"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// nestedConditions has blocks 0 -> 1, 2 and 1 -> 3, 4, see testdata/numbers.go
+func nestedConditions(t *testing.T) *ssa.Function {
+	pkgs, err := loadPackages("", "numbers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkgs[0].Func("nestedConditions")
+}
+
+func blockReasons(c *Coverage) map[int]UnreachedReason {
+	reasons := make(map[int]UnreachedReason)
+	for _, b := range c.Blocks {
+		if !b.Covered {
+			reasons[b.Index] = b.Reason
+		}
+	}
+	return reasons
+}
+
+func TestCoverage_Infeasible(t *testing.T) {
+	fn := nestedConditions(t)
+	c := newCoverage(fn, []Testcase{{path: []int{0, 2}}, {path: []int{0, 1, 4}}}, newUnreachedLog(), false)
+	if covered, all := c.CoveredBlocks(); covered != 4 || all != 5 {
+		t.Errorf("got %d/%d blocks; want 4/5", covered, all)
+	}
+	if covered, all := c.CoveredBranches(); covered != 3 || all != 4 {
+		t.Errorf("got %d/%d branches; want 3/4", covered, all)
+	}
+	if got, want := c.UncoveredLines(), map[int]UnreachedReason{43: ReasonInfeasible}; !maps.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestCoverage_SolverTimeout(t *testing.T) {
+	fn := nestedConditions(t)
+	log := newUnreachedLog()
+	log.edge(1, 3, ReasonSolverTimeout)
+	c := newCoverage(fn, []Testcase{{path: []int{0, 2}}, {path: []int{0, 1, 4}}}, log, false)
+	if got, want := blockReasons(c), map[int]UnreachedReason{3: ReasonSolverTimeout}; !maps.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	for _, b := range c.Branches {
+		if b.From == 1 && b.To == 3 && b.Reason != ReasonSolverTimeout {
+			t.Errorf("got branch %+v; want solver timeout", b)
+		}
+	}
+}
+
+func TestCoverage_DepthLimitPropagated(t *testing.T) {
+	fn := nestedConditions(t)
+	log := newUnreachedLog()
+	log.block(1, ReasonDepthLimit)
+	log.block(1, ReasonInfeasible)
+	c := newCoverage(fn, []Testcase{{path: []int{0, 2}}}, log, false)
+	want := map[int]UnreachedReason{1: ReasonDepthLimit, 3: ReasonDepthLimit, 4: ReasonDepthLimit}
+	if got := blockReasons(c); !maps.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestCoverage_Aborted(t *testing.T) {
+	fn := nestedConditions(t)
+	c := newCoverage(fn, nil, newUnreachedLog(), true)
+	for _, b := range c.Blocks {
+		if b.Covered || b.Reason != ReasonUnsupported {
+			t.Errorf("got block %+v; want unsupported", b)
+		}
+	}
+}
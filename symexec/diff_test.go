@@ -0,0 +1,45 @@
+package symexec
+
+import "testing"
+
+func TestUnifiedDiff_Equal(t *testing.T) {
+	if got := unifiedDiff("a.go", "a\nb\n", "a\nb\n"); got != "" {
+		t.Errorf("got %q; want empty diff", got)
+	}
+}
+
+func TestUnifiedDiff_NewFile(t *testing.T) {
+	want := "--- a.go\n+++ a.go\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := unifiedDiff("a.go", "", "a\nb\n"); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	new := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n"
+	want := "--- a.go\n+++ a.go\n" +
+		"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+		"@@ -14,3 +14,4 @@\n 14\n 15\n 16\n+17\n"
+	if got := unifiedDiff("a.go", old, new); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_MergedHunk(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n"
+	new := "1\n2\n3\n4\n5\n6\n7\n"
+	want := "--- a.go\n+++ a.go\n@@ -5,4 +5,3 @@\n 5\n 6\n 7\n-8\n"
+	if got := unifiedDiff("a.go", old, new); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	if !isGenerated([]byte(generatedHeader + "\n\npackage main\n")) {
+		t.Errorf("file with header should be generated")
+	}
+	if isGenerated([]byte("package main\n\n" + generatedHeader + "\n")) {
+		t.Errorf("header after package clause should be ignored")
+	}
+}
@@ -1,91 +1,122 @@
 package symexec
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"go/token"
 	"go/types"
+	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aclements/go-z3/z3"
 	"golang.org/x/tools/go/ssa"
 )
 
-const maxDepth = 100
-
 func Dynamic() {
-	os.Chdir("testdata")
+	const dir = "testdata"
 
-	testcases, err := os.ReadDir("./")
+	testcases, err := os.ReadDir(dir)
 	if err != nil {
 		panic(err)
 	}
 
+	a := NewAnalyzer(Options{Logger: NewLogger(os.Stdout, LogDebug, false)})
 	for _, tc := range testcases {
 		if tc.IsDir() || strings.HasSuffix(tc.Name(), "_test.go") {
 			continue
 		}
-		r := AnalyzeFileDynamic(tc.Name())
-		GenerateTests(tc.Name(), r)
-	}
-}
-
-func AnalyzeFileDynamic(filename string) map[*ssa.Function][]Testcase {
-	main := buildPackage(filename)
-	res := make(map[*ssa.Function][]Testcase, 0)
-	for _, v := range main.Members {
-		if fn, ok := v.(*ssa.Function); ok && fn.Name() != "init" {
-			res[fn] = dynamicFunction(fn, main)
+		r, err := a.AnalyzeFile(filepath.Join(dir, tc.Name()))
+		if err != nil {
+			fmt.Println("[ERROR]", err)
+			continue
 		}
-		if obj, ok := v.(*ssa.Type); ok {
-			named := obj.Type().(*types.Named)
-			n := named.NumMethods()
-			for i := 0; i < n; i++ {
-				fn := main.Prog.FuncValue(named.Method(i))
-				res[fn] = dynamicFunction(fn, main)
-			}
+		fmt.Println(":: generating tests")
+		if err := GenerateTests(r, GenerateOptions{}); err != nil {
+			fmt.Println("[ERROR]", err)
 		}
 	}
-	return res
 }
 
-func dynamicFunction(fn *ssa.Function, pkg *ssa.Package) []Testcase {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("[ERROR]", r)
-			fmt.Println(string(debug.Stack()))
-		}
-	}()
-	fmt.Println("::", "analyzing function", "'"+fn.Name()+"'")
-	fmt.Println("::", "printing SSA blocks")
-	printBlocks(fn)
-	fmt.Println("::", "execute")
-	return execute(fn, pkg, &RandomQueue{})
+func (a *Analyzer) dynamicFunction(fn *ssa.Function, pkg *ssa.Package) ([]Testcase, error) {
+	name := FunctionName(fn)
+	a.logger.Info("analyzing function", "function", name)
+	if a.logger.Enabled(context.Background(), slog.LevelDebug) {
+		blocks := &strings.Builder{}
+		printBlocks(blocks, fn)
+		a.logger.Debug("SSA blocks", "function", name, "blocks", blocks.String())
+	}
+	testcases, err := a.execute(fn, pkg, a.current.strategy.newQueue())
+	if err == nil {
+		a.logger.Info("function analyzed", "function", name, "testcases", len(testcases))
+	}
+	return testcases, err
 }
 
 type State struct {
 	nextFrameId int
 	depth       int
 	frames      []*Frame
+	// panicking is set until panic is recovered, panicValue is the argument of panic.
+	panicking  bool
+	panicValue Var
+	// goroutine is the id of running goroutine, frames are nil if it blocked or exited.
+	// Analyzed function runs in goroutine 0.
+	goroutine       int
+	goroutines      []*goroutine
+	nextGoroutineId int
+	preemptions     int
+	channels        []*channel
+	// sent is the number of sent values.
+	sent int
+	// segments are parts of goroutines in order they were executed, running goroutine is not included.
+	segments []Formula
 }
 
 func (s *State) copy() *State {
 	stateCopy := &State{}
-	for _, frame := range s.frames {
-		stateCopy.frames = append(stateCopy.frames, frame.copy())
+	stateCopy.frames = copyFrames(s.frames)
+	for _, g := range s.goroutines {
+		stateCopy.goroutines = append(stateCopy.goroutines, g.copy())
+	}
+	for _, c := range s.channels {
+		stateCopy.channels = append(stateCopy.channels, c.copy())
+	}
+	stateCopy.nextFrameId = s.nextFrameId
+	stateCopy.depth = s.depth
+	stateCopy.panicking = s.panicking
+	stateCopy.panicValue = s.panicValue
+	stateCopy.goroutine = s.goroutine
+	stateCopy.nextGoroutineId = s.nextGoroutineId
+	stateCopy.preemptions = s.preemptions
+	stateCopy.sent = s.sent
+	// segments are not changed
+	stateCopy.segments = slices.Clip(s.segments)
+	return stateCopy
+}
+
+func copyFrames(frames []*Frame) []*Frame {
+	var framesCopy []*Frame
+	for _, frame := range frames {
+		framesCopy = append(framesCopy, frame.copy())
 	}
 	// patch in-progress dynamic calls
-	for i := 0; i+1 < len(stateCopy.frames); i++ {
-		caller := stateCopy.frames[i]
-		callee := stateCopy.frames[i+1]
+	for i := 0; i+1 < len(framesCopy); i++ {
+		caller := framesCopy[i]
+		callee := framesCopy[i+1]
 		if _, ok := caller.call.Body[len(caller.call.Body)-1].(*DynamicCall); !ok {
 			panic("not a dynamic call")
 		}
 		caller.call.Body[len(caller.call.Body)-1] = callee.call
 	}
-	stateCopy.nextFrameId = s.nextFrameId
-	stateCopy.depth = s.depth
-	return stateCopy
+	return framesCopy
 }
 
 func (s *State) currentFrame() *Frame {
@@ -93,7 +124,14 @@ func (s *State) currentFrame() *Frame {
 }
 
 func (s *State) formula() Formula {
-	return And{s.frames[0].call.Body}
+	if len(s.segments) == 0 {
+		return And{s.frames[0].call.Body}
+	}
+	segments := slices.Clone(s.segments)
+	if s.frames != nil {
+		segments = append(segments, s.segment())
+	}
+	return And{segments}
 }
 
 type Frame struct {
@@ -103,6 +141,19 @@ type Frame struct {
 	call       *DynamicCall
 	nextBlock  int
 	nextInstr  int
+	// defers are deferred calls in order of defer statements.
+	defers []deferred
+	// unwinding is set when deferred calls are run because of panic.
+	unwinding bool
+	// iterations are numbers of steps of range statements by names of their iterators.
+	iterations map[string]int
+}
+
+// deferred is a call with function value and arguments evaluated by defer statement.
+type deferred struct {
+	call  *ssa.CallCommon
+	value Var
+	args  []Var
 }
 
 func (frame *Frame) push(f Formula) {
@@ -111,18 +162,35 @@ func (frame *Frame) push(f Formula) {
 
 func (frame *Frame) newVar(reg Register) Var {
 	tmp := &TempRegister{
-		name: reg.Name(),
+		name: registerName(reg),
 		t:    reg.Type(),
 	}
-	if frame.id > 0 {
+	// constants are the same in all frames
+	if frame.id > 0 && !isConstant(tmp.name) {
 		tmp.name = fmt.Sprintf("%d#%s", frame.id, tmp.name)
 	}
 	return NewVar(tmp)
 }
 
+// params are variables of free variables and parameters of frame function.
+// Free variables of closures are passed before parameters.
+func (frame *Frame) params() []Var {
+	var params []Var
+	for _, fv := range frame.function.FreeVars {
+		tmp := &TempRegister{t: fv.Type(), name: fv.Name()}
+		params = append(params, frame.newVar(tmp))
+	}
+	for _, p := range frame.function.Params {
+		tmp := &TempRegister{t: p.Type(), name: p.Name()}
+		params = append(params, frame.newVar(tmp))
+	}
+	return params
+}
+
 func (frame *Frame) copy() *Frame {
 	var blockOrder []int
 	var body []Formula
+	var defers []deferred
 	return &Frame{
 		id:         frame.id,
 		function:   frame.function,
@@ -134,13 +202,33 @@ func (frame *Frame) copy() *Frame {
 			Params: frame.call.Params,
 			Body:   append(body, frame.call.Body...),
 		},
-		nextBlock: frame.nextBlock,
-		nextInstr: frame.nextInstr,
+		nextBlock:  frame.nextBlock,
+		nextInstr:  frame.nextInstr,
+		defers:     append(defers, frame.defers...),
+		unwinding:  frame.unwinding,
+		iterations: maps.Clone(frame.iterations),
 	}
 }
 
-func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
-	var testcases []Testcase
+func (a *Analyzer) execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) (testcases []Testcase, err error) {
+	// instruction and state being executed, for errors
+	var current ssa.Instruction
+	var currentState *State
+	defer func() {
+		if r := recover(); r != nil {
+			if currentState != nil {
+				a.unreached.block(currentState.main().nextBlock, ReasonUnsupported)
+			}
+			analysisErr := newAnalysisError(fn, current, r)
+			var u *UnsupportedError
+			if errors.As(analysisErr, &u) {
+				a.logger.Error("analysis failed", "error", analysisErr)
+			} else {
+				a.logger.Error("analysis failed", "error", analysisErr, "stack", string(debug.Stack()))
+			}
+			testcases, err = nil, analysisErr
+		}
+	}()
 	entryPoint := &DynamicCall{
 		Result: Var{},
 		Name:   fn.Name(),
@@ -152,18 +240,34 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 	queue.push(&State{frames: []*Frame{entryFrame}})
 	for !queue.empty() {
 		state := queue.pop()
+		currentState = state
 		state.depth += 1
-		if state.depth >= maxDepth {
-			fmt.Println("[WARNING] max depth reached")
+		if state.depth >= a.current.maxDepth {
+			a.logger.Warn("max depth reached", "function", fn.Name(), "path", fmt.Sprint(state.main().blockOrder))
+			a.unreached.block(state.main().nextBlock, ReasonDepthLimit)
+			continue
+		}
+		if state.frames == nil {
+			if tc, status := a.schedule(queue, state, fn); status == statusSat && !interleaved(state, testcases, tc) {
+				testcases = append(testcases, tc)
+			}
 			continue
 		}
 		frame := state.currentFrame()
+		if frame.unwinding {
+			if tc, status := a.unwind(queue, state, fn, pkg); status == statusSat && !interleaved(state, testcases, tc) {
+				testcases = append(testcases, tc)
+			}
+			continue
+		}
+		a.logger.Log(context.Background(), LevelTrace, "execute block", "function", frame.function.Name(), "block", frame.nextBlock, "depth", state.depth)
 		block := frame.function.Blocks[frame.nextBlock]
 	instructionLoop:
 		for index, instr := range block.Instrs {
 			if index < frame.nextInstr {
 				continue
 			}
+			current = instr
 			if index == 0 {
 				frame.blockOrder = append(frame.blockOrder, frame.nextBlock)
 			} else {
@@ -171,35 +275,35 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 			}
 			switch v := instr.(type) {
 			case *ssa.BinOp:
-				frame.push(BinOp{
+				binOp := BinOp{
 					Result: frame.newVar(v),
 					Left:   frame.newVar(v.X),
 					Op:     v.Op.String(),
 					Right:  frame.newVar(v.Y),
-				})
-			case *ssa.If:
-				{
-					thenState := state.copy()
-					thenFrame := thenState.currentFrame()
-					thenFrame.nextBlock = v.Block().Succs[0].Index
-					thenFrame.push(Condition{
-						Cond:   frame.newVar(v.Cond),
-						IsTrue: true,
-					})
-					if _, sat := solve(fn, thenState.formula()); sat {
-						queue.push(thenState)
-					}
 				}
-				{
-					elseState := state.copy()
-					elseFrame := elseState.currentFrame()
-					elseFrame.nextBlock = v.Block().Succs[1].Index
-					elseFrame.push(Condition{
+				if !canDivideByZero(v) {
+					frame.push(binOp)
+					break
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: binOp.Result.Name + ".ok"})
+				binOp.Ok = &ok
+				frame.push(binOp)
+				a.check(queue, state, fn, ok, "integer divide by zero", index+1)
+				break instructionLoop
+			case *ssa.If:
+				for i, isTrue := range []bool{true, false} {
+					branchState := state.copy()
+					branchFrame := branchState.currentFrame()
+					branchFrame.nextBlock = v.Block().Succs[i].Index
+					branchFrame.push(Condition{
 						Cond:   frame.newVar(v.Cond),
-						IsTrue: false,
+						IsTrue: isTrue,
 					})
-					if _, sat := solve(fn, elseState.formula()); sat {
-						queue.push(elseState)
+					switch _, status := a.solve(fn, branchState.formula()); status {
+					case statusSat:
+						queue.push(branchState)
+					case statusUnknown:
+						a.unreachedBranch(state, v.Block().Succs[i].Index)
 					}
 				}
 				break instructionLoop
@@ -218,15 +322,37 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 				if len(state.frames) > 1 {
 					state.frames = state.frames[:len(state.frames)-1]
 					queue.push(state)
+				} else if state.goroutine != 0 {
+					state.suspend(true, nil)
+					queue.push(state)
 				} else {
-					if model, sat := solve(fn, state.formula()); sat {
-						fmt.Println("found solution for path:", state.frames[0].blockOrder)
-						fmt.Println(model)
-						testcases = append(testcases, Testcase{model: model})
+					switch tc, status := a.solve(fn, state.formula()); status {
+					case statusSat:
+						a.logger.Debug("found solution", "path", fmt.Sprint(state.frames[0].blockOrder), "model", tc.model.String())
+						tc.path = state.frames[0].blockOrder
+						if !interleaved(state, testcases, tc) {
+							testcases = append(testcases, tc)
+						}
+					case statusUnknown:
+						a.unreached.block(frame.nextBlock, ReasonSolverTimeout)
 					}
 				}
 				break instructionLoop
 			case *ssa.UnOp:
+				if v.Op == token.ARROW {
+					frame.nextInstr = index + 1
+					op := &chanOp{
+						result:   frame.newVar(v),
+						commaOk:  v.CommaOk,
+						blocking: true,
+					}
+					a.resolve(state, fn, []Var{frame.newVar(v.X)}, nil, func(branchState *State, ids []int) {
+						op := *op
+						op.cases = []chanCase{{ch: ids[0]}}
+						a.communicate(queue, branchState, &op)
+					})
+					break instructionLoop
+				}
 				frame.push(UnOp{
 					Result: frame.newVar(v),
 					Arg:    frame.newVar(v.X),
@@ -238,38 +364,168 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 					args = append(args, frame.newVar(a))
 				}
 				name := removeArgs(v.Call.String())
-				if IsBuiltIn(name) {
+				callee := v.Call.StaticCallee()
+				if mock := a.mock(callee, frame.function, pkg); mock != nil {
+					a.logger.Log(context.Background(), LevelTrace, "mocked call", "callee", callee.String(), "mock", mock.Name())
+					callee, name = mock, mock.Name()
+				}
+				switch {
+				case isRecover(&v.Call):
+					frame.push(Convert{
+						Result: frame.newVar(v),
+						Arg:    state.recover(),
+					})
+				case isClose(&v.Call):
+					frame.nextInstr = index + 1
+					a.resolve(state, fn, args, nil, func(branchState *State, ids []int) {
+						msg, ok := branchState.closeChan(ids[0])
+						switch {
+						case msg != "":
+							// the check always fails, channel is known on this path
+							a.check(queue, branchState, fn, Var{Name: "false", Type: types.Typ[types.Bool], Constant: true}, msg, index+1)
+						case ok:
+							a.yield(queue, branchState)
+						}
+					})
+					break instructionLoop
+				case IsBuiltIn(name):
 					frame.push(BuiltInCall{
 						Result: frame.newVar(v),
 						Name:   name,
 						Args:   args,
 					})
-				} else {
-					fn := v.Call.StaticCallee()
-					if fn.Package().String() != pkg.String() {
-						panic("external calls are not supported")
+				case isSymbolic(callee, symbolicMake):
+					// result is unconstrained
+				case isSymbolic(callee, symbolicAssume):
+					frame.push(Condition{
+						Cond:   args[0],
+						IsTrue: true,
+					})
+				default:
+					a.enter(queue, state, fn, pkg, &v.Call, callee, name, frame.newVar(v.Call.Value), frame.newVar(v), args, index+1)
+					break instructionLoop
+				}
+			case *ssa.Defer:
+				// function value and arguments are evaluated by defer statement
+				k := len(frame.defers)
+				d := deferred{call: &v.Call}
+				for i, arg := range append([]ssa.Value{v.Call.Value}, v.Call.Args...) {
+					if _, ok := arg.(*ssa.Builtin); ok {
+						continue
 					}
-					nextCall := &DynamicCall{
-						Result: frame.newVar(v),
-						Name:   name,
-						Args:   args,
-						Params: nil,
-						Body:   nil,
+					tmp := frame.newVar(&TempRegister{t: arg.Type(), name: fmt.Sprintf("defer%d.%d", k, i)})
+					frame.push(Convert{
+						Result: tmp,
+						Arg:    frame.newVar(arg),
+					})
+					if i == 0 {
+						d.value = tmp
+					} else {
+						d.args = append(d.args, tmp)
 					}
-					frame.push(nextCall)
-					frame.nextInstr = index + 1
-					state.nextFrameId++
-					nextFrame := &Frame{id: state.nextFrameId, function: fn, call: nextCall}
-					state.frames = append(state.frames, nextFrame)
-					for _, p := range fn.Params {
-						tmp := &TempRegister{t: p.Type(), name: p.Name()}
-						nextCall.Params = append(nextCall.Params, nextFrame.newVar(tmp))
+				}
+				frame.defers = append(frame.defers, d)
+			case *ssa.RunDefers:
+				// RunDefers is executed again when deferred call returns
+				if a.runDeferred(queue, state, fn, pkg, index) {
+					break instructionLoop
+				}
+			case *ssa.Panic:
+				state.panicking = true
+				state.panicValue = frame.newVar(v.X)
+				frame.unwinding = true
+				queue.push(state)
+				break instructionLoop
+			case *ssa.Go:
+				a.spawn(state, pkg, &v.Call)
+				frame.nextInstr = index + 1
+				a.yield(queue, state)
+				break instructionLoop
+			case *ssa.MakeChan:
+				a.makeChan(state, v)
+			case *ssa.Send:
+				frame.nextInstr = index + 1
+				value := state.snapshot(frame.newVar(v.X))
+				a.resolve(state, fn, []Var{frame.newVar(v.Chan)}, nil, func(branchState *State, ids []int) {
+					a.communicate(queue, branchState, &chanOp{
+						cases:    []chanCase{{ch: ids[0], send: true, value: value}},
+						blocking: true,
+					})
+				})
+				break instructionLoop
+			case *ssa.Select:
+				frame.nextInstr = index + 1
+				op := &chanOp{
+					result:   frame.newVar(v),
+					isSelect: true,
+					blocking: v.Blocking,
+				}
+				var chans []Var
+				recv := 0
+				for _, st := range v.States {
+					c := chanCase{send: st.Dir == types.SendOnly}
+					if c.send {
+						c.value = state.snapshot(frame.newVar(st.Send))
+					} else {
+						c.recv = recv
+						recv++
 					}
-					if _, sat := solve(fn, state.formula()); sat {
-						queue.push(state)
+					op.cases = append(op.cases, c)
+					chans = append(chans, frame.newVar(st.Chan))
+				}
+				a.resolve(state, fn, chans, nil, func(branchState *State, ids []int) {
+					op := *op
+					op.cases = slices.Clone(op.cases)
+					for k := range op.cases {
+						op.cases[k].ch = ids[k]
 					}
-					break instructionLoop
+					a.communicate(queue, branchState, &op)
+				})
+				break instructionLoop
+			case *ssa.MakeClosure:
+				var bindings []Var
+				for _, b := range v.Bindings {
+					bindings = append(bindings, frame.newVar(b))
+				}
+				frame.push(MakeClosure{
+					Result:   frame.newVar(v),
+					Fn:       v.Fn.(*ssa.Function).String(),
+					Bindings: bindings,
+				})
+			case *ssa.MakeInterface:
+				frame.push(MakeInterface{
+					Result: frame.newVar(v),
+					X:      frame.newVar(v.X),
+				})
+			case *ssa.TypeAssert:
+				if isErrorType(v.X.Type()) {
+					panic(unsupported("type assertion", "of error '%s'", v))
+				}
+				ta := TypeAssert{
+					Result:       frame.newVar(v),
+					X:            frame.newVar(v.X),
+					AssertedType: v.AssertedType,
+					CommaOk:      v.CommaOk,
+				}
+				if v.CommaOk {
+					frame.push(ta)
+					break
 				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: ta.Result.Name + ".ok"})
+				ta.Ok = &ok
+				frame.push(ta)
+				a.check(queue, state, fn, ok, "interface conversion", index+1)
+				break instructionLoop
+			case *ssa.ChangeType:
+				frame.push(Convert{
+					Result: frame.newVar(v),
+					Arg:    frame.newVar(v.X),
+				})
+			case *ssa.ChangeInterface:
+				frame.push(Convert{
+					Result: frame.newVar(v),
+					Arg:    frame.newVar(v.X),
+				})
 			case *ssa.Convert:
 				frame.push(Convert{
 					Result: frame.newVar(v),
@@ -289,16 +545,29 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 						}
 					}
 				}
+				// value is unchanged when edge refers to phi itself
+				if v.Edges[mostRecent] == v {
+					break
+				}
 				frame.push(Convert{
 					Result: frame.newVar(v),
 					Arg:    frame.newVar(v.Edges[mostRecent]),
 				})
 			case *ssa.IndexAddr:
-				frame.push(IndexAddr{
+				indexAddr := IndexAddr{
 					Result: frame.newVar(v),
 					Array:  frame.newVar(v.X),
 					Index:  frame.newVar(v.Index),
-				})
+				}
+				if isCheckedIndex(v.X, v.Index) {
+					frame.push(indexAddr)
+					break
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: indexAddr.Result.Name + ".ok"})
+				indexAddr.Ok = &ok
+				frame.push(indexAddr)
+				a.check(queue, state, fn, ok, "index out of range", index+1)
+				break instructionLoop
 			case *ssa.FieldAddr:
 				frame.push(FieldAddr{
 					Result: frame.newVar(v),
@@ -310,21 +579,467 @@ func execute(fn *ssa.Function, pkg *ssa.Package, queue Queue) []Testcase {
 					Addr:  frame.newVar(v.Addr),
 					Value: frame.newVar(v.Val),
 				})
+			case *ssa.Alloc:
+				frame.push(Alloc{
+					Result: frame.newVar(v),
+				})
+			case *ssa.Index:
+				ix := Index{
+					Result: frame.newVar(v),
+					X:      frame.newVar(v.X),
+					Index:  frame.newVar(v.Index),
+				}
+				if isCheckedIndex(v.X, v.Index) {
+					frame.push(ix)
+					break
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: ix.Result.Name + ".ok"})
+				ix.Ok = &ok
+				frame.push(ix)
+				a.check(queue, state, fn, ok, "index out of range", index+1)
+				break instructionLoop
+			case *ssa.Slice:
+				slice := Slice{
+					Result: frame.newVar(v),
+					X:      frame.newVar(v.X),
+				}
+				if v.Low != nil {
+					low := frame.newVar(v.Low)
+					slice.Low = &low
+				}
+				if v.High != nil {
+					high := frame.newVar(v.High)
+					slice.High = &high
+				}
+				if v.Max != nil {
+					max := frame.newVar(v.Max)
+					slice.Max = &max
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: slice.Result.Name + ".ok"})
+				slice.Ok = &ok
+				frame.push(slice)
+				a.check(queue, state, fn, ok, "slice bounds out of range", index+1)
+				break instructionLoop
+			case *ssa.MakeSlice:
+				ms := MakeSlice{
+					Result: frame.newVar(v),
+					Len:    frame.newVar(v.Len),
+					Cap:    frame.newVar(v.Cap),
+				}
+				if ms.Len.Constant && ms.Cap.Constant {
+					// constant length out of range doesn't compile
+					frame.push(ms)
+					break
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: ms.Result.Name + ".ok"})
+				ms.Ok = &ok
+				frame.push(ms)
+				a.check(queue, state, fn, ok, "makeslice: len out of range", index+1)
+				break instructionLoop
+			case *ssa.MakeMap:
+				frame.push(MakeMap{
+					Result: frame.newVar(v),
+				})
+			case *ssa.MapUpdate:
+				update := MapUpdate{
+					Map:   frame.newVar(v.Map),
+					Key:   frame.newVar(v.Key),
+					Value: frame.newVar(v.Value),
+				}
+				ok := frame.newVar(&TempRegister{t: types.Typ[types.Bool], name: fmt.Sprintf("update%d.ok", index)})
+				update.Ok = &ok
+				frame.push(update)
+				a.check(queue, state, fn, ok, "assignment to entry in nil map", index+1)
+				break instructionLoop
+			case *ssa.Lookup:
+				if _, ok := v.X.Type().Underlying().(*types.Map); !ok {
+					panic(unsupported("lookup", "in '%s'", v.X.Type()))
+				}
+				frame.push(Lookup{
+					Result:  frame.newVar(v),
+					Map:     frame.newVar(v.X),
+					Key:     frame.newVar(v.Index),
+					CommaOk: v.CommaOk,
+				})
+			case *ssa.Range:
+				// iterated value is evaluated by range statement
+				iter := frame.newVar(v).Name
+				if frame.iterations == nil {
+					frame.iterations = make(map[string]int)
+				}
+				frame.iterations[iter] = 0
+				frame.push(Convert{
+					Result: frame.newVar(&TempRegister{t: v.X.Type(), name: iter + ".x"}),
+					Arg:    frame.newVar(v.X),
+				})
+				if _, ok := v.X.Type().Underlying().(*types.Map); !ok {
+					frame.push(Convert{
+						Result: frame.newVar(&TempRegister{t: types.Typ[types.Int], name: iter + ".pos0"}),
+						Arg:    Var{Name: "0", Type: types.Typ[types.Int], Constant: true},
+					})
+				}
+			case *ssa.Next:
+				frame.push(frame.next(v))
+			case *ssa.Extract:
+				frame.push(Extract{
+					Result: frame.newVar(v),
+					Tuple:  frame.newVar(v.Tuple),
+					Index:  v.Index,
+				})
 			default:
-				panic(fmt.Sprint("unknown instruction: '", v.String(), "'"))
+				panic(unsupported(fmt.Sprintf("%T instruction", v), "%s", v))
+			}
+		}
+	}
+	return testcases, nil
+}
+
+// canDivideByZero tells if v is integer division or remainder by variable, constant divisors are not zero because compiler checks them.
+func canDivideByZero(v *ssa.BinOp) bool {
+	if v.Op != token.QUO && v.Op != token.REM {
+		return false
+	}
+	_, isConst := v.Y.(*ssa.Const)
+	return isInteger(v.Y.Type()) && !isConst
+}
+
+// isCheckedIndex tells if index of array x is constant, such indexes are in range because compiler checks them.
+func isCheckedIndex(x ssa.Value, index ssa.Value) bool {
+	t := x.Type().Underlying()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem().Underlying()
+	}
+	_, isConst := index.(*ssa.Const)
+	return isArray(t) && isConst
+}
+
+// next is the next step of iteration over string or map, it does not depend on the order of map entries.
+func (frame *Frame) next(v *ssa.Next) Formula {
+	rng := v.Iter.(*ssa.Range)
+	iter := frame.newVar(rng).Name
+	i := frame.iterations[iter]
+	frame.iterations[iter]++
+	x := frame.newVar(&TempRegister{t: rng.X.Type(), name: iter + ".x"})
+	if v.IsString {
+		return NextRune{
+			Result:  frame.newVar(v),
+			Str:     x,
+			Pos:     frame.newVar(&TempRegister{t: types.Typ[types.Int], name: fmt.Sprintf("%s.pos%d", iter, i)}),
+			NextPos: frame.newVar(&TempRegister{t: types.Typ[types.Int], name: fmt.Sprintf("%s.pos%d", iter, i+1)}),
+		}
+	}
+	keyT := rng.X.Type().Underlying().(*types.Map).Key()
+	var keys []Var
+	for k := 0; k <= i; k++ {
+		keys = append(keys, frame.newVar(&TempRegister{t: keyT, name: fmt.Sprintf("%s.key%d", iter, k)}))
+	}
+	return NextEntry{
+		Result: frame.newVar(v),
+		Map:    x,
+		Index:  i,
+		Key:    keys[i],
+		Prev:   keys[:i],
+	}
+}
+
+// enter makes call that is not built-in, state is queued for every feasible callee.
+// Current frame continues from instruction next when callee returns.
+func (a *Analyzer) enter(queue Queue, state *State, fn *ssa.Function, pkg *ssa.Package, common *ssa.CallCommon, callee *ssa.Function, name string, value Var, result Var, args []Var, next int) {
+	switch {
+	case common.IsInvoke():
+		a.invoke(queue, state, fn, pkg, common, value, result, args, next)
+	case callee == nil && isFunc(common.Value.Type()):
+		a.dispatch(queue, state, fn, pkg, common, value, result, args, next)
+	case callee == nil || callee.Blocks == nil:
+		panic(unsupported("external call", "%s", common.Value))
+	default:
+		if mc, ok := common.Value.(*ssa.MakeClosure); ok && callee == mc.Fn {
+			var bindings []Var
+			for _, b := range mc.Bindings {
+				bindings = append(bindings, state.currentFrame().newVar(b))
+			}
+			args = append(bindings, args...)
+		}
+		a.call(queue, state, fn, callee, name, result, args, next)
+	}
+}
+
+// call enters callee from current frame, state is queued if the call is feasible.
+func (a *Analyzer) call(queue Queue, state *State, fn *ssa.Function, callee *ssa.Function, name string, result Var, args []Var, next int) {
+	frame := state.currentFrame()
+	nextCall := &DynamicCall{
+		Result: result,
+		Name:   name,
+		Args:   args,
+		Params: nil,
+		Body:   nil,
+	}
+	frame.push(nextCall)
+	frame.nextInstr = next
+	state.nextFrameId++
+	nextFrame := &Frame{id: state.nextFrameId, function: callee, call: nextCall}
+	state.frames = append(state.frames, nextFrame)
+	nextCall.Params = nextFrame.params()
+	switch _, status := a.solve(fn, state.formula()); status {
+	case statusSat:
+		queue.push(state)
+	case statusUnknown:
+		a.unreached.block(state.main().nextBlock, ReasonSolverTimeout)
+	}
+}
+
+// invoke forks state for every dynamic type of receiver that implements the interface
+// and for nil receiver, calls on it panic.
+func (a *Analyzer) invoke(queue Queue, state *State, fn *ssa.Function, pkg *ssa.Package, common *ssa.CallCommon, recv Var, result Var, args []Var, next int) {
+	recvT := common.Value.Type()
+	if isErrorType(recvT) {
+		panic(unsupported("method call", "on error '%s'", common))
+	}
+	prog := fn.Prog
+	impls := implementations(a.dynamicTypes, recvT)
+	if len(impls) == 0 {
+		panic(unsupported("method call", "no dynamic type implements '%s'", recvT))
+	}
+	nilState := state.copy()
+	nilFrame := nilState.currentFrame()
+	ok := nilFrame.newVar(&TempRegister{t: types.Typ[types.Bool], name: result.Name + ".recv.ok"})
+	nilFrame.push(BinOp{Result: ok, Left: recv, Op: "!=", Right: Var{Name: "nil", Type: recvT, Constant: true}})
+	nilFrame.push(Condition{Cond: ok, IsTrue: false})
+	a.check(queue, nilState, fn, ok, "invalid memory address or nil pointer dereference", next)
+	for _, t := range impls {
+		callee := prog.MethodValue(prog.MethodSets.MethodSet(t).Lookup(common.Method.Pkg(), common.Method.Name()))
+		if mock := a.mock(callee, state.currentFrame().function, pkg); mock != nil {
+			callee = mock
+		}
+		if callee == nil || callee.Blocks == nil {
+			panic(unsupported("external call", "%s", callee))
+		}
+		branchState := state.copy()
+		frame := branchState.currentFrame()
+		concrete := NewVar(&TempRegister{t: t, name: result.Name + ".recv"})
+		frame.push(TypeAssert{
+			Result:       concrete,
+			X:            recv,
+			AssertedType: t,
+		})
+		a.call(queue, branchState, fn, callee, callee.String(), result, append([]Var{concrete}, args...), next)
+	}
+}
+
+// dispatch forks state for every function that call through function value can call and for nil value, calls of it panic.
+// Input function values are unknown, see UnknownCall.
+func (a *Analyzer) dispatch(queue Queue, state *State, fn *ssa.Function, pkg *ssa.Package, common *ssa.CallCommon, value Var, result Var, args []Var, next int) {
+	nilState := state.copy()
+	nilFrame := nilState.currentFrame()
+	ok := nilFrame.newVar(&TempRegister{t: types.Typ[types.Bool], name: result.Name + ".func.ok"})
+	nilFrame.push(BinOp{Result: ok, Left: value, Op: "!=", Right: Var{Name: "nil", Type: value.Type, Constant: true}})
+	nilFrame.push(Condition{Cond: ok, IsTrue: false})
+	a.check(queue, nilState, fn, ok, "invalid memory address or nil pointer dereference", next)
+	for _, target := range targets(a.funcValues, common.Value.Type()) {
+		callee := target
+		if mock := a.mock(callee, state.currentFrame().function, pkg); mock != nil {
+			callee = mock
+		}
+		if callee.Blocks == nil {
+			panic(unsupported("external call", "%s", callee))
+		}
+		branchState := state.copy()
+		frame := branchState.currentFrame()
+		var freeVars []Var
+		for _, fv := range target.FreeVars {
+			freeVars = append(freeVars, NewVar(&TempRegister{t: fv.Type(), name: result.Name + "." + fv.Name()}))
+		}
+		frame.push(Dispatch{
+			Func:     value,
+			Fn:       target.String(),
+			FreeVars: freeVars,
+		})
+		if callee != target {
+			// mocks have no free variables
+			freeVars = nil
+		}
+		a.call(queue, branchState, fn, callee, callee.String(), result, append(freeVars, args...), next)
+	}
+	frame := state.currentFrame()
+	frame.push(Dispatch{
+		Func: value,
+	})
+	frame.push(UnknownCall{
+		Result: result,
+		Func:   value,
+	})
+	frame.nextInstr = next
+	switch _, status := a.solve(fn, state.formula()); status {
+	case statusSat:
+		queue.push(state)
+	case statusUnknown:
+		a.unreached.block(state.main().nextBlock, ReasonSolverTimeout)
+	}
+}
+
+// runDeferred runs deferred calls of current frame in reverse order until one of them is entered.
+// It returns false if there are no such calls left.
+func (a *Analyzer) runDeferred(queue Queue, state *State, fn *ssa.Function, pkg *ssa.Package, next int) bool {
+	frame := state.currentFrame()
+	for len(frame.defers) > 0 {
+		d := frame.defers[len(frame.defers)-1]
+		frame.defers = frame.defers[:len(frame.defers)-1]
+		k := len(frame.defers)
+		result := frame.newVar(&TempRegister{t: d.call.Signature().Results(), name: fmt.Sprintf("defer%d", k)})
+		if sig := d.call.Signature(); sig.Results().Len() == 1 {
+			result.Type = sig.Results().At(0).Type()
+		}
+		if b, ok := d.call.Value.(*ssa.Builtin); ok {
+			// recover called by defer statement directly does not stop panic
+			if IsBuiltIn(b.Name()) {
+				frame.push(BuiltInCall{
+					Result: result,
+					Name:   b.Name(),
+					Args:   d.args,
+				})
 			}
+			continue
+		}
+		callee := d.call.StaticCallee()
+		name := removeArgs(d.call.String())
+		if mock := a.mock(callee, frame.function, pkg); mock != nil {
+			callee, name = mock, mock.Name()
+		}
+		a.enter(queue, state, fn, pkg, d.call, callee, name, d.value, result, d.args, next)
+		return true
+	}
+	return false
+}
+
+// unwind runs deferred calls of current frame after panic.
+// If panic is recovered, frame returns from its recover block, otherwise panic is propagated to the caller.
+// Testcase is returned if panic is not recovered by analyzed function.
+func (a *Analyzer) unwind(queue Queue, state *State, fn *ssa.Function, pkg *ssa.Package) (Testcase, solverStatus) {
+	frame := state.currentFrame()
+	if a.runDeferred(queue, state, fn, pkg, frame.nextInstr) {
+		return Testcase{}, statusUnsat
+	}
+	frame.unwinding = false
+	if !state.panicking {
+		if frame.function.Recover == nil {
+			panic(unsupported("recover", "in '%s' without recover block", frame.function))
+		}
+		frame.nextBlock = frame.function.Recover.Index
+		frame.nextInstr = 0
+		queue.push(state)
+		return Testcase{}, statusUnsat
+	}
+	if len(state.frames) > 1 {
+		state.frames = state.frames[:len(state.frames)-1]
+		state.currentFrame().unwinding = true
+		queue.push(state)
+		return Testcase{}, statusUnsat
+	}
+	main := state.main()
+	tc, status := a.solve(fn, state.formula())
+	switch status {
+	case statusSat:
+		a.logger.Debug("found panic", "path", fmt.Sprint(main.blockOrder), "goroutine", state.goroutine, "model", tc.model.String())
+		tc.path = main.blockOrder
+		tc.panics = true
+		// panic in goroutine crashes the program, test can't recover it
+		tc.goPanic = state.goroutine != 0
+	case statusUnknown:
+		a.unreached.block(main.nextBlock, ReasonSolverTimeout)
+	}
+	return tc, status
+}
+
+// check continues from instruction next of current frame if runtime check ok holds,
+// otherwise the state panics with runtime error msg like Go does.
+func (a *Analyzer) check(queue Queue, state *State, fn *ssa.Function, ok Var, msg string, next int) {
+	state.currentFrame().nextInstr = next
+	for _, isTrue := range []bool{true, false} {
+		branchState := state.copy()
+		branchFrame := branchState.currentFrame()
+		branchFrame.push(Condition{
+			Cond:   ok,
+			IsTrue: isTrue,
+		})
+		if !isTrue {
+			err := branchFrame.newVar(&TempRegister{t: types.NewInterfaceType(nil, nil), name: ok.Name + ".err"})
+			branchFrame.push(MakeInterface{
+				Result: err,
+				X:      Var{Name: strconv.Quote("runtime error: " + msg), Type: types.Typ[types.String], Constant: true},
+			})
+			branchState.panicking = true
+			branchState.panicValue = err
+			branchFrame.unwinding = true
+		}
+		switch _, status := a.solve(fn, branchState.formula()); status {
+		case statusSat:
+			queue.push(branchState)
+		case statusUnknown:
+			a.unreached.block(state.main().nextBlock, ReasonSolverTimeout)
+		}
+	}
+}
+
+// recover stops panic if it is called by deferred call of panicking frame.
+// The result is the value of panic, nil if there is no panic.
+func (s *State) recover() Var {
+	if s.panicking && len(s.frames) > 1 && s.frames[len(s.frames)-2].unwinding {
+		s.panicking = false
+		return s.panicValue
+	}
+	return Var{Name: "nil", Type: types.NewInterfaceType(nil, nil), Constant: true}
+}
+
+// unreachedBranch records that branch to block was not explored because of solver timeout.
+// Branches in called functions are recorded for the block of analyzed function that made the call.
+func (a *Analyzer) unreachedBranch(state *State, to int) {
+	if state.goroutine == 0 && len(state.frames) == 1 {
+		a.unreached.edge(state.frames[0].nextBlock, to, ReasonSolverTimeout)
+	} else {
+		a.unreached.block(state.main().nextBlock, ReasonSolverTimeout)
+	}
+}
+
+// mock returns function from analyzed package that replaces callee, nil if callee is not mocked.
+// Calls inside the mock itself are not replaced.
+func (a *Analyzer) mock(callee *ssa.Function, caller *ssa.Function, pkg *ssa.Package) *ssa.Function {
+	if callee == nil {
+		return nil
+	}
+	var mock *ssa.Function
+	if name, ok := a.current.mocks[callee.String()]; ok {
+		mock = pkg.Func(name)
+		if mock == nil {
+			panic(unsupported("mock", "'%s' for '%s' not found in package '%s'", name, callee, pkg.Pkg.Path()))
 		}
+	} else if callee.Pkg != nil && callee.Signature.Recv() == nil {
+		mock = pkg.Func(callee.Pkg.Pkg.Name() + "__" + callee.Name())
+	}
+	if mock == caller {
+		return nil
 	}
-	return testcases
+	return mock
 }
 
-func solve(fn *ssa.Function, f Formula) (model *z3.Model, sat bool) {
+type solverStatus int
+
+const (
+	statusUnsat solverStatus = iota
+	statusSat
+	// statusUnknown is returned on timeouts and solver errors.
+	statusUnknown
+)
+
+// solve returns testcase without path if formula is satisfiable.
+func (a *Analyzer) solve(fn *ssa.Function, f Formula) (Testcase, solverStatus) {
 	vars := make(map[string]Var, 0)
 	f.ScanVars(vars)
-	vars[resultSpecialVar] = Var{
-		Name:     resultSpecialVar,
-		Type:     fn.Signature.Results().At(0).Type(),
-		Constant: false,
+	if t := resultType(fn.Signature); t != nil {
+		vars[resultSpecialVar] = Var{
+			Name:     resultSpecialVar,
+			Type:     t,
+			Constant: false,
+		}
 	}
 
 	z3ctx := z3.NewContext(nil)
@@ -337,17 +1052,32 @@ func solve(fn *ssa.Function, f Formula) (model *z3.Model, sat bool) {
 		varsUsed: make(map[string]struct{}),
 		varCount: make(map[string]int),
 
-		fieldsMemory:      make(map[string][]z3.Array),
-		valuesMemory:      make(map[string]z3.Array),
-		arrayValuesMemory: make(map[string]z3.Array),
-		arrayLenMemory:    make(map[string]z3.Array),
+		fieldsMemory:       make(map[string][]z3.Array),
+		valuesMemory:       make(map[string]z3.Array),
+		arrayBackingMemory: make(map[string]z3.Array),
+		arrayOffsetMemory:  make(map[string]z3.Array),
+		arrayLenMemory:     make(map[string]z3.Array),
+		arrayCapMemory:     make(map[string]z3.Array),
+		mapValuesMemory:    make(map[string]z3.Array),
+		mapPresentMemory:   make(map[string]z3.Array),
+		mapLenMemory:       make(map[string]z3.Array),
+		mapKeys:            make(map[string][]z3.Value),
+
+		bitVectors: a.current.integers == IntegersBitVector,
 
 		floatSort:   z3ctx.FloatSort(11, 53),
+		float32Sort: z3ctx.FloatSort(8, 24),
 		complexSort: z3ctx.UninterpretedSort("complex128"),
 		stringSort:  z3ctx.UninterpretedSort("string"),
 
 		addrSort: z3ctx.UninterpretedSort("$addr"),
+
+		dynamicTypes:  slices.Clone(a.dynamicTypes),
+		closureMemory: make(map[string]z3.Array),
 	}
+	ctx.initHeap()
+	// path formulas of floats only are solved much slower without integer functions
+	ctx.heapOf(ctx.nilAddr)
 
 	for _, v := range vars {
 		ctx.AddType(v.Type)
@@ -357,17 +1087,62 @@ func solve(fn *ssa.Function, f Formula) (model *z3.Model, sat bool) {
 		ctx.AddVar(v.Name, v.Name, v.Type)
 	}
 
-	return solveWithTimeout(f.Encode(ctx).(z3.Bool), ctx)
+	for _, p := range fn.Params {
+		if isChan(p.Type()) {
+			// input channels are nil, analyzed function can't communicate with callers
+			ctx.asserts = append(ctx.asserts, ctx.vars[p.Name()].(z3.Int).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+			continue
+		}
+		switch v := ctx.vars[p.Name()].(type) {
+		case *Pointer:
+			ctx.asserts = append(ctx.asserts, ctx.isInput(v.addr))
+		case *SymArray:
+			ctx.asserts = append(ctx.asserts, ctx.isInput(v.addr))
+		case *FixedArray:
+			ctx.asserts = append(ctx.asserts, ctx.isInput(v.addr))
+		case *SymMap:
+			ctx.asserts = append(ctx.asserts, ctx.isInput(v.addr))
+		case *String:
+			ctx.asserts = append(ctx.asserts, v.len.LE(ctx.FromInt(maxStringLen, ctx.IntSort()).(z3.Int)))
+		case *Interface:
+			ctx.constrainInputInterface(v, p.Type())
+		case *Closure:
+			// input function values are unknown or nil
+			ctx.asserts = append(ctx.asserts, ctx.target(v.addr).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+		}
+	}
+
+	formula := f.Encode(ctx).(z3.Bool)
+	ctx.constrainInputMaps(fn.Params)
+	model, status := a.solveWithTimeout(formula, ctx)
+	if status != statusSat {
+		return Testcase{}, status
+	}
+	ifaces, boxes := ctx.inputInterfaces(fn.Params, model)
+	return Testcase{
+		model:   model,
+		maps:    ctx.inputMaps(fn.Params, model),
+		strings: ctx.stringValues(fn, model),
+		arrays:  ctx.arrayValues(fn, model),
+		ifaces:  ifaces,
+		boxes:   boxes,
+		funcs:   ctx.inputFuncs(fn.Params, model),
+	}, status
 }
 
-func solveWithTimeout(f z3.Bool, ctx *EncodingContext) (model *z3.Model, sat bool) {
+func (a *Analyzer) solveWithTimeout(f z3.Bool, ctx *EncodingContext) (model *z3.Model, status solverStatus) {
+	start := time.Now()
+	a.stats.Queries++
 	defer func() {
+		a.stats.Time += time.Since(start)
 		if r := recover(); r != nil {
-			fmt.Println("[WARNING]", r)
+			a.stats.Unknown++
+			a.logger.Warn("solver failed", "error", fmt.Sprint(r))
+			model, status = nil, statusUnknown
 		}
 	}()
 
-	ctx.Config().SetUint("timeout", 15*1000)
+	ctx.Config().SetUint("timeout", uint(a.current.timeout.Milliseconds()))
 
 	solver := z3.NewSolver(ctx.Context)
 	solver.Assert(f)
@@ -379,10 +1154,13 @@ func solveWithTimeout(f z3.Bool, ctx *EncodingContext) (model *z3.Model, sat boo
 	if err != nil {
 		panic(err)
 	}
+	a.logger.Log(context.Background(), LevelTrace, "solver query", "sat", sat, "time", time.Since(start))
 
 	if sat {
-		return solver.Model(), true
+		a.stats.Sat++
+		return solver.Model(), statusSat
 	} else {
-		return nil, false
+		a.stats.Unsat++
+		return nil, statusUnsat
 	}
 }
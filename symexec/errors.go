@@ -0,0 +1,109 @@
+package symexec
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// UnsupportedError means that analysis hit Go feature that is not supported (yet).
+type UnsupportedError struct {
+	// Feature is a short name that is the same for all errors caused by the same feature, e.g. 'external call'.
+	Feature string
+	// Detail is specific to the place where error happened.
+	Detail string
+}
+
+// AnalysisError tells where analysis of function was aborted.
+// Err is *UnsupportedError for unsupported features, anything else is a bug.
+type AnalysisError struct {
+	Function *ssa.Function
+	// Instr is the instruction being executed, it can be nil.
+	// Errors when encoding formulas are reported at instruction that required solving, e.g. 'if' or 'return'.
+	Instr ssa.Instruction
+	Pos   token.Position
+	Err   error
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s is not supported", e.Feature)
+	}
+	return fmt.Sprintf("%s is not supported: %s", e.Feature, e.Detail)
+}
+
+func (e *AnalysisError) Error() string {
+	var where string
+	if e.Pos.IsValid() {
+		where = e.Pos.String() + ": "
+	}
+	if e.Instr != nil {
+		return fmt.Sprintf("%sanalysis of '%s' failed at '%s': %s", where, e.Function.Name(), e.Instr, e.Err)
+	}
+	return fmt.Sprintf("%sanalysis of '%s' failed: %s", where, e.Function.Name(), e.Err)
+}
+
+func (e *AnalysisError) Unwrap() error {
+	return e.Err
+}
+
+// unsupported is thrown (as panic) where feature is not supported, analysis of function is aborted.
+func unsupported(feature string, format string, args ...any) *UnsupportedError {
+	return &UnsupportedError{Feature: feature, Detail: fmt.Sprintf(format, args...)}
+}
+
+// newAnalysisError converts value recovered from panic.
+func newAnalysisError(fn *ssa.Function, instr ssa.Instruction, r any) *AnalysisError {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	pos := fn.Pos()
+	if instr != nil && instr.Pos().IsValid() {
+		pos = instr.Pos()
+	}
+	return &AnalysisError{
+		Function: fn,
+		Instr:    instr,
+		Pos:      fn.Prog.Fset.Position(pos),
+		Err:      err,
+	}
+}
+
+// BlockedFeature is a feature that aborted analysis of functions.
+type BlockedFeature struct {
+	Feature   string
+	Functions []*ssa.Function
+}
+
+// Unsupported summarizes unsupported features, most common first.
+func (r *Result) Unsupported() []BlockedFeature {
+	functions := make(map[string][]*ssa.Function)
+	for _, pkg := range r.Packages {
+		for _, fr := range pkg.Functions {
+			if fr.Err == nil {
+				continue
+			}
+			feature := "internal error"
+			var u *UnsupportedError
+			if errors.As(fr.Err, &u) {
+				feature = u.Feature
+			}
+			functions[feature] = append(functions[feature], fr.Function)
+		}
+	}
+	var blocked []BlockedFeature
+	for feature, fns := range functions {
+		blocked = append(blocked, BlockedFeature{Feature: feature, Functions: fns})
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		if len(blocked[i].Functions) != len(blocked[j].Functions) {
+			return len(blocked[i].Functions) > len(blocked[j].Functions)
+		}
+		return blocked[i].Feature < blocked[j].Feature
+	})
+	return blocked
+}
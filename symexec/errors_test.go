@@ -0,0 +1,37 @@
+package symexec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnsupported_Summary(t *testing.T) {
+	pkgs, err := loadPackages("", "numbers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fns := packageFunctions(pkgs[0])
+	res := &Result{Packages: []*PackageResult{{
+		Package: pkgs[0],
+		Functions: []*FunctionResult{
+			{Function: fns[0], Err: newAnalysisError(fns[0], nil, unsupported("conversion", "from 'a' to 'b'"))},
+			{Function: fns[1]},
+			{Function: fns[2], Err: newAnalysisError(fns[2], nil, "index out of range")},
+			{Function: fns[3], Err: newAnalysisError(fns[3], nil, unsupported("conversion", "from 'c' to 'd'"))},
+		},
+	}}}
+	blocked := res.Unsupported()
+	if len(blocked) != 2 {
+		t.Fatalf("got %v; want 2 features", blocked)
+	}
+	if blocked[0].Feature != "conversion" || len(blocked[0].Functions) != 2 {
+		t.Errorf("got %v; want 'conversion' blocking 2 functions", blocked[0])
+	}
+	if blocked[1].Feature != "internal error" || blocked[1].Functions[0] != fns[2] {
+		t.Errorf("got %v; want 'internal error' blocking '%s'", blocked[1], fns[2])
+	}
+	var u *UnsupportedError
+	if !errors.As(res.Packages[0].Functions[0].Err, &u) {
+		t.Errorf("analysis error should wrap unsupported error")
+	}
+}
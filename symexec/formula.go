@@ -24,11 +24,13 @@ type Var struct {
 	Constant bool
 }
 
+// BinOp is 'x op y', Ok is set for integer division and remainder if divisor can be zero, it tells if it is not.
 type BinOp struct {
 	Result Var
 	Left   Var
 	Op     string
 	Right  Var
+	Ok     *Var
 }
 
 type UnOp struct {
@@ -70,10 +72,13 @@ type Convert struct {
 	Arg    Var
 }
 
+// IndexAddr is '&x[i]' for slices and pointers to arrays.
+// Ok is set if index is in range, index out of range is infeasible if it is nil.
 type IndexAddr struct {
 	Result Var
 	Array  Var
 	Index  Var
+	Ok     *Var
 }
 
 type FieldAddr struct {
@@ -92,8 +97,157 @@ type Store struct {
 	Value Var
 }
 
+type Alloc struct {
+	Result Var
+}
+
+type Extract struct {
+	Result Var
+	Tuple  Var
+	Index  int
+}
+
+// Index is 'x[i]' for values that are not addressable, i.e. strings and arrays.
+// Ok is set if index is in range, index out of range is infeasible if it is nil.
+type Index struct {
+	Result Var
+	X      Var
+	Index  Var
+	Ok     *Var
+}
+
+// Slice is 'x[low:high:max]', missing bounds are nil.
+// Ok is set if bounds are in range, bounds out of range are infeasible if it is nil.
+type Slice struct {
+	Result Var
+	X      Var
+	Low    *Var
+	High   *Var
+	Max    *Var
+	Ok     *Var
+}
+
+// MakeSlice allocates slice with new backing array of Cap elements.
+// Ok is set if length is in range (0 <= Len <= Cap), length out of range is infeasible if it is nil.
+type MakeSlice struct {
+	Result Var
+	Len    Var
+	Cap    Var
+	Ok     *Var
+}
+
+type MakeMap struct {
+	Result Var
+}
+
+// MapUpdate is 'm[k] = v', Ok is set if map is not nil, assignment to nil map is infeasible if it is nil.
+type MapUpdate struct {
+	Map   Var
+	Key   Var
+	Value Var
+	Ok    *Var
+}
+
+// Lookup is 'm[k]', result is tuple of value and presence if CommaOk is set.
+type Lookup struct {
+	Result  Var
+	Map     Var
+	Key     Var
+	CommaOk bool
+}
+
+// MakeInterface boxes X in interface value.
+type MakeInterface struct {
+	Result Var
+	X      Var
+}
+
+// TypeAssert is 'x.(T)', result is tuple of value and success if CommaOk is set.
+// Without CommaOk, Ok is set if assertion succeeds, failed assertion is infeasible if it is nil.
+type TypeAssert struct {
+	Result       Var
+	X            Var
+	AssertedType types.Type
+	CommaOk      bool
+	Ok           *Var
+}
+
+// MakeClosure binds free variables of function Fn.
+type MakeClosure struct {
+	Result   Var
+	Fn       string
+	Bindings []Var
+}
+
+// Dispatch selects function Fn as target of function value Func and loads its free variables.
+// Empty Fn is unknown target of input function value.
+type Dispatch struct {
+	Func     Var
+	Fn       string
+	FreeVars []Var
+}
+
+// UnknownCall is call of input function value Func, its results are unknown, see unknownResult.
+type UnknownCall struct {
+	Result Var
+	Func   Var
+}
+
+// NextEntry is Index-th step of iteration over Map, Key is the key of entry that is different from keys of previous steps Prev.
+// Result is tuple of success, key and value, iteration stops when Index reaches length of map.
+type NextEntry struct {
+	Result Var
+	Map    Var
+	Index  int
+	Key    Var
+	Prev   []Var
+}
+
+// NextRune is a step of iteration over Str that decodes rune at byte Pos, NextPos is the position of the next rune.
+// Result is tuple of success, position and rune, iteration stops at the end of string.
+type NextRune struct {
+	Result  Var
+	Str     Var
+	Pos     Var
+	NextPos Var
+}
+
+// MakeChan gives number ID to new channel, nil channel is 0.
+type MakeChan struct {
+	Result Var
+	ID     int
+}
+
+// ChanIs tells that Chan is the channel with number ID.
+type ChanIs struct {
+	Chan Var
+	ID   int
+}
+
+// Receive is '<-ch' that receives Value, it is nil if channel is closed.
+// Result is tuple of value and success if CommaOk is set.
+type Receive struct {
+	Result  Var
+	Value   *Var
+	CommaOk bool
+}
+
+// SelectCase is the result of select that proceeds with case Index, default case is -1.
+// Recv is the index of case among receive cases or -1 for other cases,
+// Value is received value that is nil if channel is closed.
+type SelectCase struct {
+	Result Var
+	Index  int
+	Recv   int
+	Value  *Var
+}
+
 func removeType(str string) string {
-	return strings.Split(str, ":")[0]
+	// string constants can contain ':'
+	if i := strings.LastIndex(str, ":"); i >= 0 {
+		return str[:i]
+	}
+	return str
 }
 
 func isConstant(str string) bool {
@@ -106,7 +260,7 @@ func removeArgs(str string) string {
 
 func NewVar(reg Register) Var {
 	return Var{
-		Name:     removeType(reg.Name()),
+		Name:     removeType(registerName(reg)),
 		Type:     reg.Type(),
 		Constant: isConstant(reg.Name()),
 	}
@@ -118,7 +272,42 @@ func (v Var) String() string {
 
 func (v Var) Encode(ctx *EncodingContext) SymValue {
 	if v.Constant {
-		switch t := v.Type.(type) {
+		if isErrorType(v.Type) && v.Name == "nil" {
+			return ctx.FromBool(false)
+		}
+		if v.Name == "nil" {
+			switch t := v.Type.Underlying().(type) {
+			case *types.Pointer:
+				return &Pointer{addr: ctx.nilAddr, t: v.Type.String(), elem: t.Elem().String(), sort: ctx.addrSort}
+			case *types.Slice:
+				return &SymArray{addr: ctx.nilAddr, t: v.Type.String(), sort: ctx.addrSort}
+			case *types.Map:
+				return &SymMap{addr: ctx.nilAddr, t: v.Type.String(), sort: ctx.addrSort}
+			case *types.Interface:
+				return &Interface{addr: ctx.nilAddr, t: v.Type.String(), sort: ctx.addrSort}
+			case *types.Signature:
+				return &Closure{addr: ctx.nilAddr, t: v.Type.String(), sort: ctx.addrSort}
+			case *types.Chan:
+				return ctx.FromInt(0, ctx.IntSort())
+			}
+		}
+		if isFunc(v.Type) {
+			return ctx.funcValue(v.Name, v.Type)
+		}
+		if t, ok := v.Type.Underlying().(*types.Array); ok {
+			// the only constant of array type is its zero value
+			backing, allocated := ctx.zeroArray(t)
+			ctx.asserts = append(ctx.asserts, allocated)
+			return &FixedArray{addr: backing, t: v.Type.String(), sort: ctx.addrSort}
+		}
+		if t, ok := v.Type.Underlying().(*types.Struct); ok {
+			// the only constant of struct type is its zero value
+			ctx.AddType(v.Type)
+			str, allocated := ctx.zeroStruct(v.Type, t)
+			ctx.asserts = append(ctx.asserts, allocated)
+			return &SymStruct{addr: str, t: v.Type.String(), sort: ctx.addrSort}
+		}
+		switch t := v.Type.Underlying().(type) {
 		case *types.Basic:
 			switch t.Kind() {
 			case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
@@ -126,13 +315,13 @@ func (v Var) Encode(ctx *EncodingContext) SymValue {
 				if err != nil {
 					panic(err)
 				}
-				return ctx.FromBigInt(new(big.Int).SetUint64(i), ctx.IntSort())
+				return ctx.intConst(new(big.Int).SetUint64(i), t)
 			case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
 				i, err := strconv.ParseInt(v.Name, 10, intSize)
 				if err != nil {
 					panic(err)
 				}
-				return ctx.FromInt(i, ctx.IntSort())
+				return ctx.intConst(big.NewInt(i), t)
 			case types.Bool:
 				b, err := strconv.ParseBool(v.Name)
 				if err != nil {
@@ -145,15 +334,27 @@ func (v Var) Encode(ctx *EncodingContext) SymValue {
 					panic(err)
 				}
 				return ctx.FromFloat64(f, ctx.floatSort)
+			case types.Float32:
+				f, err := strconv.ParseFloat(v.Name, float32Size)
+				if err != nil {
+					panic(err)
+				}
+				return ctx.FromFloat64(f, ctx.float32Sort)
 			case types.Complex128:
 				c, err := strconv.ParseComplex(v.Name, complexSize)
 				if err != nil {
 					panic(err)
 				}
 				return ctx.FromComplex128(c)
+			case types.String:
+				s, err := strconv.Unquote(v.Name)
+				if err != nil {
+					panic(err)
+				}
+				return ctx.FromString(s)
 			}
 		}
-		panic(fmt.Sprintf("unknown constant '%s' of type '%s'", v.Name, v.Type))
+		panic(unsupported("constant", "'%s' of type '%s'", v.Name, v.Type))
 	}
 	if sv, ok := ctx.vars[v.Name]; ok {
 		if ctx.varsUsed != nil {
@@ -198,11 +399,17 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 	res := bo.Result.Encode(ctx)
 	left := bo.Left.Encode(ctx)
 	right := bo.Right.Encode(ctx)
+	// bit-vectors of unsigned integers are compared, divided and shifted as unsigned
+	unsigned := isUnsigned(bo.Left.Type)
 	switch bo.Op {
 	case "+":
 		switch left := left.(type) {
+		case *String:
+			return ctx.eq(res, ctx.concat(left, right.(*String)))
 		case z3.Int:
 			return res.(z3.Int).Eq(left.Add(right.(z3.Int)))
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Add(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Float).Eq(left.Add(right.(z3.Float)))
 		case *Complex:
@@ -215,6 +422,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Int).Eq(left.Sub(right.(z3.Int)))
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Sub(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Float).Eq(left.Sub(right.(z3.Float)))
 		case *Complex:
@@ -227,6 +436,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Int).Eq(left.Mul(right.(z3.Int)))
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Mul(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Float).Eq(left.Mul(right.(z3.Float)))
 		case *Complex:
@@ -242,8 +453,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 		}
 	case "/":
 		switch left := left.(type) {
-		case z3.Int:
-			return res.(z3.Int).Eq(left.Div(right.(z3.Int)))
+		case z3.Int, z3.BV:
+			return bo.encodeIntDiv(ctx, res, left, right, unsigned)
 		case z3.Float:
 			return res.(z3.Float).Eq(left.Div(right.(z3.Float)))
 		case *Complex:
@@ -259,55 +470,119 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 				resCx.imag.Eq(b.Mul(c).Sub(a.Mul(d)).Div(denom)))
 		}
 	case "%":
-		switch left := left.(type) {
-		case z3.Int:
-			return res.(z3.Int).Eq(left.Mod(right.(z3.Int)))
+		switch left.(type) {
+		case z3.Int, z3.BV:
+			return bo.encodeIntDiv(ctx, res, left, right, unsigned)
 		}
 	case ">":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.GT(right.(z3.Int)))
+		case z3.BV:
+			if unsigned {
+				return res.(z3.Bool).Eq(left.UGT(right.(z3.BV)))
+			}
+			return res.(z3.Bool).Eq(left.SGT(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.GT(right.(z3.Float)))
+		case *String:
+			return res.(z3.Bool).Eq(ctx.stringLess(right.(*String), left))
 		}
 	case ">=":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.GE(right.(z3.Int)))
+		case z3.BV:
+			if unsigned {
+				return res.(z3.Bool).Eq(left.UGE(right.(z3.BV)))
+			}
+			return res.(z3.Bool).Eq(left.SGE(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.GE(right.(z3.Float)))
+		case *String:
+			return res.(z3.Bool).Eq(ctx.stringLess(left, right.(*String)).Not())
 		}
 	case "<":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.LT(right.(z3.Int)))
+		case z3.BV:
+			if unsigned {
+				return res.(z3.Bool).Eq(left.ULT(right.(z3.BV)))
+			}
+			return res.(z3.Bool).Eq(left.SLT(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.LT(right.(z3.Float)))
+		case *String:
+			return res.(z3.Bool).Eq(ctx.stringLess(left, right.(*String)))
 		}
 	case "<=":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.LE(right.(z3.Int)))
+		case z3.BV:
+			if unsigned {
+				return res.(z3.Bool).Eq(left.ULE(right.(z3.BV)))
+			}
+			return res.(z3.Bool).Eq(left.SLE(right.(z3.BV)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.LE(right.(z3.Float)))
+		case *String:
+			return res.(z3.Bool).Eq(ctx.stringLess(right.(*String), left).Not())
 		}
 	case "==":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.Eq(right.(z3.Int)))
+		case z3.BV:
+			return res.(z3.Bool).Eq(left.Eq(right.(z3.BV)))
 		case z3.Bool:
 			return res.(z3.Bool).Eq(left.Eq(right.(z3.Bool)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.IEEEEq(right.(z3.Float)))
+		case *Pointer:
+			return res.(z3.Bool).Eq(left.addr.Eq(right.(*Pointer).addr))
+		case *SymArray:
+			return res.(z3.Bool).Eq(left.addr.Eq(right.(*SymArray).addr))
+		case *FixedArray:
+			eq, valid := ctx.arrayEq(bo.Left.Type.Underlying().(*types.Array), left.addr, right.(*FixedArray).addr)
+			return res.(z3.Bool).Eq(eq).And(valid)
+		case *SymMap:
+			return res.(z3.Bool).Eq(left.addr.Eq(right.(*SymMap).addr))
+		case *Interface:
+			return res.(z3.Bool).Eq(ctx.interfaceEq(left, right.(*Interface)))
+		case *Closure:
+			return res.(z3.Bool).Eq(left.addr.Eq(right.(*Closure).addr))
+		case *String:
+			right := right.(*String)
+			return res.(z3.Bool).Eq(ctx.stringEq(left, right, min(left.max, right.max)))
 		}
 	case "!=":
 		switch left := left.(type) {
 		case z3.Int:
 			return res.(z3.Bool).Eq(left.NE(right.(z3.Int)))
+		case z3.BV:
+			return res.(z3.Bool).Eq(left.NE(right.(z3.BV)))
 		case z3.Bool:
 			return res.(z3.Bool).Eq(left.NE(right.(z3.Bool)))
 		case z3.Float:
 			return res.(z3.Bool).Eq(left.IEEEEq(right.(z3.Float)).Not())
+		case *Pointer:
+			return res.(z3.Bool).Eq(left.addr.NE(right.(*Pointer).addr))
+		case *SymArray:
+			return res.(z3.Bool).Eq(left.addr.NE(right.(*SymArray).addr))
+		case *FixedArray:
+			eq, valid := ctx.arrayEq(bo.Left.Type.Underlying().(*types.Array), left.addr, right.(*FixedArray).addr)
+			return res.(z3.Bool).Eq(eq.Not()).And(valid)
+		case *SymMap:
+			return res.(z3.Bool).Eq(left.addr.NE(right.(*SymMap).addr))
+		case *Interface:
+			return res.(z3.Bool).Eq(ctx.interfaceEq(left, right.(*Interface)).Not())
+		case *Closure:
+			return res.(z3.Bool).Eq(left.addr.NE(right.(*Closure).addr))
+		case *String:
+			right := right.(*String)
+			return res.(z3.Bool).Eq(ctx.stringEq(left, right, min(left.max, right.max)).Not())
 		}
 	case "<<":
 		switch left := left.(type) {
@@ -315,6 +590,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 			leftBV := left.ToBV(intSize)
 			rightBV := right.(z3.Int).ToBV(intSize)
 			return res.(z3.Int).Eq(leftBV.Lsh(rightBV).SToInt())
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Lsh(ctx.shiftCount(right.(z3.BV), bo.Right.Type, bo.Left.Type)))
 		}
 	case ">>":
 		switch left := left.(type) {
@@ -322,6 +599,12 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 			leftBV := left.ToBV(intSize)
 			rightBV := right.(z3.Int).ToBV(intSize)
 			return res.(z3.Int).Eq(leftBV.SRsh(rightBV).SToInt())
+		case z3.BV:
+			count := ctx.shiftCount(right.(z3.BV), bo.Right.Type, bo.Left.Type)
+			if unsigned {
+				return res.(z3.BV).Eq(left.URsh(count))
+			}
+			return res.(z3.BV).Eq(left.SRsh(count))
 		}
 	case "^":
 		switch left := left.(type) {
@@ -329,6 +612,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 			leftBV := left.ToBV(intSize)
 			rightBV := right.(z3.Int).ToBV(intSize)
 			return res.(z3.Int).Eq(leftBV.Xor(rightBV).SToInt())
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Xor(right.(z3.BV)))
 		}
 	case "&":
 		switch left := left.(type) {
@@ -336,6 +621,8 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 			leftBV := left.ToBV(intSize)
 			rightBV := right.(z3.Int).ToBV(intSize)
 			return res.(z3.Int).Eq(leftBV.And(rightBV).SToInt())
+		case z3.BV:
+			return res.(z3.BV).Eq(left.And(right.(z3.BV)))
 		}
 	case "|":
 		switch left := left.(type) {
@@ -343,15 +630,63 @@ func (bo BinOp) Encode(ctx *EncodingContext) SymValue {
 			leftBV := left.ToBV(intSize)
 			rightBV := right.(z3.Int).ToBV(intSize)
 			return res.(z3.Int).Eq(leftBV.Or(rightBV).SToInt())
+		case z3.BV:
+			return res.(z3.BV).Eq(left.Or(right.(z3.BV)))
+		}
+	}
+	panic(unsupported("binary operation", "'%s' for sort '%s'", bo.Op, left.Sort()))
+}
+
+// encodeIntDiv encodes integer division and remainder like in Go,
+// quotient is truncated toward zero and remainder has the sign of dividend.
+func (bo BinOp) encodeIntDiv(ctx *EncodingContext, res SymValue, left SymValue, right SymValue, unsigned bool) z3.Bool {
+	var eq, nonZero z3.Bool
+	switch left := left.(type) {
+	case z3.Int:
+		right := right.(z3.Int)
+		zero := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+		one := ctx.FromInt(1, ctx.IntSort()).(z3.Int)
+		// Euclidean remainder is never negative, it differs from Go if dividend is negative and remainder is not zero
+		q, m := left.Div(right), left.Mod(right)
+		adjust := left.LT(zero).And(m.NE(zero))
+		positive := right.GT(zero)
+		if bo.Op == "/" {
+			truncated := positive.IfThenElse(q.Add(one), q.Sub(one))
+			eq = res.(z3.Int).Eq(adjust.IfThenElse(truncated, q).(z3.Int))
+		} else {
+			truncated := positive.IfThenElse(m.Sub(right), m.Add(right))
+			eq = res.(z3.Int).Eq(adjust.IfThenElse(truncated, m).(z3.Int))
+		}
+		nonZero = right.NE(zero)
+	case z3.BV:
+		// bit-vector division is truncated already
+		right := right.(z3.BV)
+		switch {
+		case bo.Op == "/" && unsigned:
+			eq = res.(z3.BV).Eq(left.UDiv(right))
+		case bo.Op == "/":
+			eq = res.(z3.BV).Eq(left.SDiv(right))
+		case unsigned:
+			eq = res.(z3.BV).Eq(left.URem(right))
+		default:
+			eq = res.(z3.BV).Eq(left.SRem(right))
 		}
+		nonZero = right.NE(ctx.FromInt(0, right.Sort()).(z3.BV))
 	}
-	panic(fmt.Sprintf("unknown binary operation '%s' for sort '%s'", bo.Op, left.Sort()))
+	if bo.Ok == nil {
+		return eq
+	}
+	bo.Ok.makeFresh(ctx)
+	return eq.And(bo.Ok.Encode(ctx).(z3.Bool).Eq(nonZero))
 }
 
 func (bo BinOp) ScanVars(vars map[string]Var) {
 	bo.Result.ScanVars(vars)
 	bo.Left.ScanVars(vars)
 	bo.Right.ScanVars(vars)
+	if bo.Ok != nil {
+		bo.Ok.ScanVars(vars)
+	}
 }
 
 func (uo UnOp) String() string {
@@ -368,17 +703,36 @@ func (uo UnOp) Encode(ctx *EncodingContext) SymValue {
 		switch result := result.(type) {
 		case z3.Int:
 			return result.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Int))
+		case z3.BV:
+			return result.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.BV))
 		case z3.Bool:
 			return result.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Bool))
 		case z3.Float:
 			return result.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Float))
 		case *SymArray:
 			return result.addr.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+		case *FixedArray:
+			// loaded array doesn't change with stores to its elements
+			backing, copied := ctx.copyArray(uo.Result.Type.Underlying().(*types.Array), ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+			return result.addr.Eq(backing).And(copied)
+		case *Pointer:
+			return result.addr.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+		case *SymMap:
+			return result.addr.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+		case *Interface:
+			return result.addr.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+		case *Closure:
+			return result.addr.Eq(ctx.valuesMemory[arg.t].Select(arg.addr).(z3.Uninterpreted))
+		case *String:
+			raw := ctx.valuesMemory[arg.t].Select(arg.addr).(z3.BV)
+			return ctx.rawEq(ctx.rawString(result), raw).And(ctx.isRawString(raw))
 		}
 	case "-":
 		switch arg := arg.(type) {
 		case z3.Int:
 			return result.(z3.Int).Eq(arg.Neg())
+		case z3.BV:
+			return result.(z3.BV).Eq(arg.Neg())
 		case z3.Float:
 			return result.(z3.Float).Eq(arg.Neg())
 		}
@@ -387,9 +741,11 @@ func (uo UnOp) Encode(ctx *EncodingContext) SymValue {
 		case z3.Int:
 			argBv := arg.ToBV(intSize)
 			return result.(z3.Int).Eq(argBv.Neg().SToInt())
+		case z3.BV:
+			return result.(z3.BV).Eq(arg.Not())
 		}
 	}
-	panic(fmt.Sprintf("unknown unary operation '%s' for sort '%s'", uo.Op, arg.Sort()))
+	panic(unsupported("unary operation", "'%s' for sort '%s'", uo.Op, arg.Sort()))
 }
 
 func (uo UnOp) ScanVars(vars map[string]Var) {
@@ -406,38 +762,24 @@ func (ret Return) String() string {
 }
 
 func (ret Return) Encode(ctx *EncodingContext) SymValue {
-	if len(ret.Results) > 1 {
-		panic("multiple return values are not supported")
+	if len(ret.Results) == 0 {
+		return ctx.FromBool(true)
 	}
-	if result, ok := ctx.vars[resultSpecialVar]; ok {
-		switch result := result.(type) {
-		case z3.Int:
-			return result.Eq(ret.Results[0].Encode(ctx).(z3.Int))
-		case z3.Bool:
-			return result.Eq(ret.Results[0].Encode(ctx).(z3.Bool))
-		case z3.Float:
-			return result.Eq(ret.Results[0].Encode(ctx).(z3.Float))
-		case *Complex:
-			arg := ret.Results[0].Encode(ctx).(*Complex)
-			return result.real.Eq(arg.real).And(result.imag.Eq(arg.imag))
-		case *String:
-			return ctx.FromBool(true) // TODO
-		case *SymArray:
-			arg := ret.Results[0].Encode(ctx).(*SymArray)
-			return result.addr.Eq(arg.addr)
-		case *Pointer:
-			arg := ret.Results[0].Encode(ctx).(*Pointer)
-			return result.addr.Eq(arg.addr)
-		}
-		panic(fmt.Sprintf("unknown return sort '%s'", result.Sort()))
+	result, ok := ctx.vars[resultSpecialVar]
+	if !ok {
+		panic("result var not found")
 	}
-	panic("result var not found")
+	if len(ret.Results) == 1 {
+		return ctx.eq(result, ret.Results[0].Encode(ctx))
+	}
+	tuple := &Tuple{}
+	for _, r := range ret.Results {
+		tuple.elems = append(tuple.elems, r.Encode(ctx))
+	}
+	return ctx.eq(result, tuple)
 }
 
 func (ret Return) ScanVars(vars map[string]Var) {
-	if len(ret.Results) > 1 {
-		panic("multiple return values are not supported")
-	}
 	for _, v := range ret.Results {
 		v.ScanVars(vars)
 	}
@@ -499,10 +841,38 @@ func (f BuiltInCall) Encode(ctx *EncodingContext) SymValue {
 	case imagFunc:
 		return f.Result.Encode(ctx).(z3.Float).Eq(f.Args[0].Encode(ctx).(*Complex).imag)
 	case lenFunc:
-		arr := f.Args[0].Encode(ctx).(*SymArray)
-		return f.Result.Encode(ctx).(z3.Int).Eq(ctx.arrayLenMemory[arr.t].Select(arr.addr).(z3.Int))
+		switch arg := f.Args[0].Encode(ctx).(type) {
+		case *SymArray:
+			return ctx.toInt(f.Result.Encode(ctx), f.Result.Type).Eq(ctx.header(arg).len)
+		case *SymMap:
+			return ctx.toInt(f.Result.Encode(ctx), f.Result.Type).Eq(ctx.mapLenMemory[arg.t].Select(arg.addr).(z3.Int))
+		case *String:
+			return ctx.toInt(f.Result.Encode(ctx), f.Result.Type).Eq(arg.len)
+		}
+	case deleteFunc:
+		m := f.Args[0].Encode(ctx).(*SymMap)
+		key := ctx.mapKey(m, f.Args[1].Encode(ctx))
+		present := ctx.mapPresentMemory[m.t].Select(m.addr).(z3.Array)
+		l := ctx.mapLenMemory[m.t].Select(m.addr).(z3.Int)
+		ctx.mapLenMemory[m.t] = ctx.mapLenMemory[m.t].Store(m.addr,
+			present.Select(key).(z3.Bool).IfThenElse(l.Sub(ctx.FromInt(1, ctx.IntSort()).(z3.Int)), l))
+		ctx.mapPresentMemory[m.t] = ctx.mapPresentMemory[m.t].Store(m.addr, present.Store(key, ctx.FromBool(false)))
+		return ctx.FromBool(true)
+	case capFunc:
+		return ctx.toInt(f.Result.Encode(ctx), f.Result.Type).Eq(ctx.header(f.Args[0].Encode(ctx).(*SymArray)).cap)
+	case copyFunc:
+		if _, ok := f.Args[1].Encode(ctx).(*SymArray); !ok {
+			panic(unsupported("builtin", "'%s' from '%s'", f.Name, f.Args[1].Type))
+		}
+		dst := ctx.header(f.Args[0].Encode(ctx).(*SymArray))
+		src := ctx.header(f.Args[1].Encode(ctx).(*SymArray))
+		n := ctx.min(dst.len, src.len)
+		elemT := f.Args[0].Type.Underlying().(*types.Slice).Elem()
+		return ctx.toInt(f.Result.Encode(ctx), f.Result.Type).Eq(n).And(ctx.copyElems(elemT, dst.backing, dst.offset, src.backing, src.offset, n))
+	case appendFunc:
+		return f.encodeAppend(ctx)
 	case mathInf:
-		arg := f.Args[0].Encode(ctx).(z3.Int)
+		arg := ctx.toInt(f.Args[0].Encode(ctx), f.Args[0].Type)
 		res := f.Result.Encode(ctx).(z3.Float)
 		return arg.GT(ctx.FromInt(0, ctx.IntSort()).(z3.Int)).IfThenElse(
 			res.Eq(ctx.FloatInf(ctx.floatSort, false)),
@@ -512,8 +882,41 @@ func (f BuiltInCall) Encode(ctx *EncodingContext) SymValue {
 		arg := f.Args[0].Encode(ctx).(z3.Float)
 		res := f.Result.Encode(ctx).(z3.Bool)
 		return res.Eq(arg.IsNaN())
+	case mathAbs:
+		arg := f.Args[0].Encode(ctx).(z3.Float)
+		res := f.Result.Encode(ctx).(z3.Float)
+		return res.Eq(arg.Abs())
+	case errorsNew, fmtErrorf:
+		return f.Result.Encode(ctx).(z3.Bool)
 	}
-	panic(fmt.Sprintf("unknown function '%s'", f.Name))
+	panic(unsupported("builtin", "'%s'", f.Name))
+}
+
+// encodeAppend reuses backing array of slice if appended elements fit in its capacity,
+// otherwise elements of slice are copied to new backing array that is grown like in Go runtime.
+func (f BuiltInCall) encodeAppend(ctx *EncodingContext) SymValue {
+	if _, ok := f.Args[1].Encode(ctx).(*SymArray); !ok {
+		panic(unsupported("builtin", "'%s' of '%s'", f.Name, f.Args[1].Type))
+	}
+	s := ctx.header(f.Args[0].Encode(ctx).(*SymArray))
+	elems := ctx.header(f.Args[1].Encode(ctx).(*SymArray))
+	res := f.Result.Encode(ctx).(*SymArray)
+	elemT := f.Result.Type.Underlying().(*types.Slice).Elem()
+	zero := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+	l := s.len.Add(elems.len)
+	fits := l.LE(s.cap)
+	backing, allocated := ctx.newBacking()
+	grown := sliceHeader{
+		backing: fits.IfThenElse(s.backing, backing).(z3.Uninterpreted),
+		offset:  fits.IfThenElse(s.offset, zero).(z3.Int),
+		len:     l,
+		cap:     fits.IfThenElse(s.cap, ctx.growCap(l, s.cap, elemT)).(z3.Int),
+	}
+	return allocated.And(
+		ctx.setHeader(res, grown),
+		ctx.copyElems(elemT, backing, zero, s.backing, s.offset, fits.IfThenElse(zero, s.len).(z3.Int)),
+		ctx.copyElems(elemT, grown.backing, grown.offset.Add(s.len), elems.backing, elems.offset, elems.len),
+	)
 }
 
 func (f BuiltInCall) ScanVars(vars map[string]Var) {
@@ -565,48 +968,59 @@ func (c Convert) String() string {
 
 func (c Convert) Encode(ctx *EncodingContext) SymValue {
 	c.Result.makeFresh(ctx)
-	switch resT := c.Result.Type.(type) {
-	case *types.Basic:
-		switch resT.Kind() {
-		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
-			switch argT := c.Arg.Type.(type) {
-			case *types.Basic:
-				switch argT.Kind() {
-				case types.Int, types.Int8, types.Int16, types.Int32, types.Int64, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
-					return c.Result.Encode(ctx).(z3.Int).Eq(c.Arg.Encode(ctx).(z3.Int))
-				}
+	// values of identical types, interface values, channels and arrays of identical types share representation
+	if types.Identical(c.Result.Type, c.Arg.Type) || isInterface(c.Result.Type) && isInterface(c.Arg.Type) || isChan(c.Result.Type) && isChan(c.Arg.Type) ||
+		isArray(c.Result.Type) && types.Identical(c.Result.Type.Underlying(), c.Arg.Type.Underlying()) {
+		return ctx.eq(c.Result.Encode(ctx), c.Arg.Encode(ctx))
+	}
+	resT, resOk := c.Result.Type.Underlying().(*types.Basic)
+	argT, argOk := c.Arg.Type.Underlying().(*types.Basic)
+	if resOk && argOk {
+		res, arg := c.Result.Encode(ctx), c.Arg.Encode(ctx)
+		resInfo, argInfo := resT.Info(), argT.Info()
+		switch {
+		case resInfo&types.IsInteger != 0 && argInfo&types.IsInteger != 0:
+			if arg, ok := arg.(z3.BV); ok {
+				return res.(z3.BV).Eq(resize(arg, argT, resT))
 			}
-		case types.Bool:
-			switch argT := c.Arg.Type.(type) {
-			case *types.Basic:
-				switch argT.Kind() {
-				case types.Bool:
-					return c.Result.Encode(ctx).(z3.Bool).Eq(c.Arg.Encode(ctx).(z3.Bool))
-				}
+			return res.(z3.Int).Eq(ctx.wrap(arg.(z3.Int), argT, resT))
+		case resInfo&types.IsFloat != 0 && argInfo&types.IsFloat != 0:
+			// float32 values are exact in float64, float64 values are rounded to nearest even like in Go
+			return res.(z3.Float).Eq(arg.(z3.Float).ToFloat(res.Sort()))
+		case resInfo&types.IsFloat != 0 && argInfo&types.IsInteger != 0:
+			// integers are rounded to nearest even like in Go
+			bv, ok := arg.(z3.BV)
+			if !ok {
+				bv = arg.(z3.Int).ToBV(intWidth(argT))
 			}
-		case types.Float64:
-			switch argT := c.Arg.Type.(type) {
-			case *types.Basic:
-				switch argT.Kind() {
-				case types.Float64:
-					return c.Result.Encode(ctx).(z3.Float).Eq(c.Arg.Encode(ctx).(z3.Float))
-				case types.Int:
-					return c.Result.Encode(ctx).(z3.Float).Eq(c.Arg.Encode(ctx).(z3.Int).ToBV(intSize).SToFloat(ctx.floatSort))
-				}
+			if isUnsigned(argT) {
+				return res.(z3.Float).Eq(bv.UToFloat(res.Sort()))
 			}
-		case types.Complex128:
-			switch argT := c.Arg.Type.(type) {
-			case *types.Basic:
-				switch argT.Kind() {
-				case types.Complex128:
-					res := c.Result.Encode(ctx).(*Complex)
-					arg := c.Arg.Encode(ctx).(*Complex)
-					return res.real.Eq(arg.real).And(res.imag.Eq(arg.imag))
-				}
+			return res.(z3.Float).Eq(bv.SToFloat(res.Sort()))
+		case resInfo&types.IsInteger != 0 && argInfo&types.IsFloat != 0:
+			// fraction is truncated like in Go
+			truncated := arg.(z3.Float).Round(z3.RoundToZero)
+			bv := truncated.ToSBV(intWidth(resT))
+			if isUnsigned(resT) {
+				bv = truncated.ToUBV(intWidth(resT))
+			}
+			if ctx.bitVectors {
+				return res.(z3.BV).Eq(bv).And(ctx.fitsInt(truncated, resT))
 			}
+			return res.(z3.Int).Eq(ctx.toInt(bv, resT)).And(ctx.fitsInt(truncated, resT))
+		case resT.Kind() == types.Bool && argT.Kind() == types.Bool:
+			return res.(z3.Bool).Eq(arg.(z3.Bool))
+		case resT.Kind() == types.Complex128 && argT.Kind() == types.Complex128:
+			res, arg := res.(*Complex), arg.(*Complex)
+			return res.real.Eq(arg.real).And(res.imag.Eq(arg.imag))
+		case resInfo&types.IsComplex != 0 && argInfo&types.IsComplex != 0:
+			// complex64 values are not encoded, only complex128 parts are float64
+			panic(unsupported("conversion", "from '%s' to '%s', complex64 is not supported", c.Arg.Type, c.Result.Type))
+		case resT.Kind() == types.String && argT.Kind() == types.String:
+			return ctx.eq(res, arg)
 		}
 	}
-	panic(fmt.Sprintf("unsupported conversion from '%s' to '%s'", c.Arg.Type, c.Result.Type))
+	panic(unsupported("conversion", "from '%s' to '%s'", c.Arg.Type, c.Result.Type))
 }
 
 func (c Convert) ScanVars(vars map[string]Var) {
@@ -620,19 +1034,36 @@ func (ia IndexAddr) String() string {
 
 func (ia IndexAddr) Encode(ctx *EncodingContext) SymValue {
 	ia.Result.makeFresh(ctx)
-	res := ia.Result.Encode(ctx).(*Pointer).addr
-	array := ia.Array.Encode(ctx).(*SymArray)
-	index := ia.Index.Encode(ctx).(z3.Int)
-	values := ctx.arrayValuesMemory[array.t].Select(array.addr).(z3.Array)
-	len := ctx.arrayLenMemory[array.t].Select(array.addr).(z3.Int)
-	value := values.Select(index).(z3.Uninterpreted)
-	return res.Eq(value).And(index.LT(len))
+	res := ia.Result.Encode(ctx).(*Pointer)
+	index := ctx.toInt(ia.Index.Encode(ctx), ia.Index.Type)
+	var backing z3.Uninterpreted
+	var i, len z3.Int
+	switch array := ia.Array.Encode(ctx).(type) {
+	case *SymArray:
+		h := ctx.header(array)
+		backing, i, len = h.backing, h.offset.Add(index), h.len
+	case *Pointer:
+		// pointer to array holds its backing array
+		backing = ctx.valuesMemory[array.t].Select(array.addr).(z3.Uninterpreted)
+		i = index
+		len = ctx.FromInt(ia.Array.Type.Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Len(), ctx.IntSort()).(z3.Int)
+	}
+	elem, valid := ctx.elemAddr(backing, i, res.t, ia.Result.Type.Underlying().(*types.Pointer).Elem())
+	inBounds := ctx.FromInt(0, ctx.IntSort()).(z3.Int).LE(index).And(index.LT(len))
+	if ia.Ok == nil {
+		return res.addr.Eq(elem).And(valid, inBounds)
+	}
+	ia.Ok.makeFresh(ctx)
+	return res.addr.Eq(elem).And(valid, ia.Ok.Encode(ctx).(z3.Bool).Eq(inBounds))
 }
 
 func (ia IndexAddr) ScanVars(vars map[string]Var) {
 	ia.Result.ScanVars(vars)
 	ia.Array.ScanVars(vars)
 	ia.Index.ScanVars(vars)
+	if ia.Ok != nil {
+		ia.Ok.ScanVars(vars)
+	}
 }
 
 func (fa FieldAddr) String() string {
@@ -646,7 +1077,7 @@ func (fa FieldAddr) Encode(ctx *EncodingContext) SymValue {
 	addr := ctx.valuesMemory[str.t].Select(str.addr).(z3.Uninterpreted)
 	fields := ctx.fieldsMemory[str.elem]
 	value := fields[fa.Field].Select(addr).(z3.Uninterpreted)
-	return res.Eq(value)
+	return res.Eq(value).And(ctx.isInput(str.addr).Implies(ctx.isInput(res)))
 }
 
 func (fa FieldAddr) ScanVars(vars map[string]Var) {
@@ -682,21 +1113,54 @@ func (s Store) String() string {
 func (s Store) Encode(ctx *EncodingContext) SymValue {
 	addr := s.Addr.Encode(ctx).(*Pointer)
 	value := s.Value.Encode(ctx)
+	switch value := value.(type) {
+	case *Pointer:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.addr)
+		return ctx.FromBool(true)
+	case *SymArray:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.addr)
+		return ctx.FromBool(true)
+	case *FixedArray:
+		// stored array doesn't change with stores to elements at addr
+		backing, copied := ctx.copyArray(s.Value.Type.Underlying().(*types.Array), value.addr)
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, backing)
+		return copied
+	case *SymMap:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.addr)
+		return ctx.FromBool(true)
+	case *Interface:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.addr)
+		return ctx.FromBool(true)
+	case *Closure:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.addr)
+		return ctx.FromBool(true)
+	case *String:
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, ctx.rawString(value))
+		return ctx.FromBool(true)
+	}
+	if isErrorType(s.Value.Type) {
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.(z3.Bool))
+		return ctx.FromBool(true)
+	}
+	if isChan(s.Value.Type) {
+		ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.(z3.Int))
+		return ctx.FromBool(true)
+	}
 	switch t := s.Value.Type.(type) {
 	case *types.Basic:
 		switch t.Kind() {
 		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
-			ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.(z3.Int))
+			ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, ctx.rawValue(value))
 			return ctx.FromBool(true)
 		case types.Bool:
 			ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.(z3.Bool))
 			return ctx.FromBool(true)
-		case types.Float64:
+		case types.Float64, types.Float32:
 			ctx.valuesMemory[addr.t] = ctx.valuesMemory[addr.t].Store(addr.addr, value.(z3.Float))
 			return ctx.FromBool(true)
 		}
 	}
-	panic(fmt.Sprintf("unsupported store to '%s'", s.Addr.Type))
+	panic(unsupported("store", "to '%s'", s.Addr.Type))
 }
 
 func (s Store) ScanVars(vars map[string]Var) {
@@ -704,6 +1168,595 @@ func (s Store) ScanVars(vars map[string]Var) {
 	s.Value.ScanVars(vars)
 }
 
+func (a Alloc) String() string {
+	return fmt.Sprintf("%s = new", a.Result)
+}
+
+func (a Alloc) Encode(ctx *EncodingContext) SymValue {
+	a.Result.makeFresh(ctx)
+	ptr := a.Result.Encode(ctx).(*Pointer)
+	elem := a.Result.Type.Underlying().(*types.Pointer).Elem()
+	return ctx.allocate(ptr.addr).And(ctx.initZero(ptr.t, ptr.addr, elem))
+}
+
+func (a Alloc) ScanVars(vars map[string]Var) {
+	a.Result.ScanVars(vars)
+}
+
+func (e Extract) String() string {
+	return fmt.Sprintf("%s == %s#%d", e.Result, e.Tuple, e.Index)
+}
+
+func (e Extract) Encode(ctx *EncodingContext) SymValue {
+	e.Result.makeFresh(ctx)
+	tuple := e.Tuple.Encode(ctx).(*Tuple)
+	return ctx.eq(e.Result.Encode(ctx), tuple.elems[e.Index])
+}
+
+func (e Extract) ScanVars(vars map[string]Var) {
+	e.Result.ScanVars(vars)
+	e.Tuple.ScanVars(vars)
+}
+
+func (ix Index) String() string {
+	return fmt.Sprintf("%s == %s[%s]", ix.Result, ix.X, ix.Index)
+}
+
+func (ix Index) Encode(ctx *EncodingContext) SymValue {
+	ix.Result.makeFresh(ctx)
+	i := ctx.toInt(ix.Index.Encode(ctx), ix.Index.Type)
+	var res, inBounds z3.Bool
+	switch x := ix.X.Encode(ctx).(type) {
+	case *String:
+		res = ctx.toInt(ix.Result.Encode(ctx), ix.Result.Type).Eq(x.at(i).UToInt())
+		inBounds = ctx.inBounds(i, i.Add(ctx.FromInt(1, ctx.IntSort()).(z3.Int)), x)
+	case *FixedArray:
+		t := ix.X.Type.Underlying().(*types.Array)
+		ptrT := types.NewPointer(t.Elem()).String()
+		elem, valid := ctx.elemAddr(x.addr, i, ptrT, t.Elem())
+		res = ctx.rawEq(ctx.rawValue(ix.Result.Encode(ctx)), ctx.valuesMemory[ptrT].Select(elem)).And(valid)
+		inBounds = ctx.FromInt(0, ctx.IntSort()).(z3.Int).LE(i).And(i.LT(ctx.FromInt(t.Len(), ctx.IntSort()).(z3.Int)))
+	default:
+		panic(unsupported("index", "of '%s'", ix.X.Type))
+	}
+	if ix.Ok == nil {
+		return res.And(inBounds)
+	}
+	ix.Ok.makeFresh(ctx)
+	return res.And(ix.Ok.Encode(ctx).(z3.Bool).Eq(inBounds))
+}
+
+func (ix Index) ScanVars(vars map[string]Var) {
+	ix.Result.ScanVars(vars)
+	ix.X.ScanVars(vars)
+	ix.Index.ScanVars(vars)
+	if ix.Ok != nil {
+		ix.Ok.ScanVars(vars)
+	}
+}
+
+func (s Slice) String() string {
+	var low, high string
+	if s.Low != nil {
+		low = s.Low.String()
+	}
+	if s.High != nil {
+		high = s.High.String()
+	}
+	if s.Max != nil {
+		return fmt.Sprintf("%s == %s[%s:%s:%s]", s.Result, s.X, low, high, s.Max)
+	}
+	return fmt.Sprintf("%s == %s[%s:%s]", s.Result, s.X, low, high)
+}
+
+func (s Slice) Encode(ctx *EncodingContext) SymValue {
+	s.Result.makeFresh(ctx)
+	switch x := s.X.Encode(ctx).(type) {
+	case *String:
+		low, high := ctx.FromInt(0, ctx.IntSort()).(z3.Int), x.len
+		if s.Low != nil {
+			low = ctx.toInt(s.Low.Encode(ctx), s.Low.Type)
+		}
+		if s.High != nil {
+			high = ctx.toInt(s.High.Encode(ctx), s.High.Type)
+		}
+		res := ctx.eq(s.Result.Encode(ctx), ctx.substring(x, low, high))
+		return s.checked(ctx, res, ctx.inBounds(low, high, x))
+	case *SymArray:
+		return s.encodeSlice(ctx, ctx.header(x))
+	case *Pointer:
+		// slice of array shares its backing array
+		n := ctx.FromInt(s.X.Type.Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Len(), ctx.IntSort()).(z3.Int)
+		return s.encodeSlice(ctx, sliceHeader{
+			backing: ctx.valuesMemory[x.t].Select(x.addr).(z3.Uninterpreted),
+			offset:  ctx.FromInt(0, ctx.IntSort()).(z3.Int),
+			len:     n,
+			cap:     n,
+		})
+	}
+	panic(unsupported("slice", "of '%s'", s.X.Type))
+}
+
+// encodeSlice makes slice of header x, it is a window of the same backing array.
+func (s Slice) encodeSlice(ctx *EncodingContext, x sliceHeader) z3.Bool {
+	zero := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+	low, high, max := zero, x.len, x.cap
+	if s.Low != nil {
+		low = ctx.toInt(s.Low.Encode(ctx), s.Low.Type)
+	}
+	if s.High != nil {
+		high = ctx.toInt(s.High.Encode(ctx), s.High.Type)
+	}
+	if s.Max != nil {
+		max = ctx.toInt(s.Max.Encode(ctx), s.Max.Type)
+	}
+	res := ctx.setHeader(s.Result.Encode(ctx).(*SymArray), sliceHeader{
+		backing: x.backing,
+		offset:  x.offset.Add(low),
+		len:     high.Sub(low),
+		cap:     max.Sub(low),
+	})
+	return s.checked(ctx, res, zero.LE(low).And(low.LE(high), high.LE(max), max.LE(x.cap)))
+}
+
+// checked gives Ok if it is set, bounds out of range are infeasible otherwise.
+func (s Slice) checked(ctx *EncodingContext, res z3.Bool, inBounds z3.Bool) z3.Bool {
+	if s.Ok == nil {
+		return res.And(inBounds)
+	}
+	s.Ok.makeFresh(ctx)
+	return res.And(s.Ok.Encode(ctx).(z3.Bool).Eq(inBounds))
+}
+
+func (s Slice) ScanVars(vars map[string]Var) {
+	s.Result.ScanVars(vars)
+	s.X.ScanVars(vars)
+	if s.Low != nil {
+		s.Low.ScanVars(vars)
+	}
+	if s.High != nil {
+		s.High.ScanVars(vars)
+	}
+	if s.Max != nil {
+		s.Max.ScanVars(vars)
+	}
+	if s.Ok != nil {
+		s.Ok.ScanVars(vars)
+	}
+}
+
+func (ms MakeSlice) String() string {
+	return fmt.Sprintf("%s = make slice %s %s", ms.Result, ms.Len, ms.Cap)
+}
+
+func (ms MakeSlice) Encode(ctx *EncodingContext) SymValue {
+	ms.Result.makeFresh(ctx)
+	l := ctx.toInt(ms.Len.Encode(ctx), ms.Len.Type)
+	c := ctx.toInt(ms.Cap.Encode(ctx), ms.Cap.Type)
+	backing, allocated := ctx.newBacking()
+	zero := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+	res := allocated.And(ctx.setHeader(ms.Result.Encode(ctx).(*SymArray), sliceHeader{backing: backing, offset: zero, len: l, cap: c}))
+	inRange := zero.LE(l).And(l.LE(c))
+	if ms.Ok == nil {
+		return res.And(inRange)
+	}
+	ms.Ok.makeFresh(ctx)
+	return res.And(ms.Ok.Encode(ctx).(z3.Bool).Eq(inRange))
+}
+
+func (ms MakeSlice) ScanVars(vars map[string]Var) {
+	ms.Result.ScanVars(vars)
+	ms.Len.ScanVars(vars)
+	ms.Cap.ScanVars(vars)
+	if ms.Ok != nil {
+		ms.Ok.ScanVars(vars)
+	}
+}
+
+func (mm MakeMap) String() string {
+	return fmt.Sprintf("%s = make map", mm.Result)
+}
+
+func (mm MakeMap) Encode(ctx *EncodingContext) SymValue {
+	mm.Result.makeFresh(ctx)
+	m := mm.Result.Encode(ctx).(*SymMap)
+	keyT := ctx.rawTypes[mm.Result.Type.Underlying().(*types.Map).Key().String()]
+	ctx.mapPresentMemory[m.t] = ctx.mapPresentMemory[m.t].Store(m.addr, ctx.ConstArray(keyT, ctx.FromBool(false)))
+	ctx.mapLenMemory[m.t] = ctx.mapLenMemory[m.t].Store(m.addr, ctx.FromInt(0, ctx.IntSort()))
+	return ctx.allocate(m.addr)
+}
+
+func (mm MakeMap) ScanVars(vars map[string]Var) {
+	mm.Result.ScanVars(vars)
+}
+
+func (mu MapUpdate) String() string {
+	return fmt.Sprintf("%s[%s] = %s", mu.Map, mu.Key, mu.Value)
+}
+
+func (mu MapUpdate) Encode(ctx *EncodingContext) SymValue {
+	m := mu.Map.Encode(ctx).(*SymMap)
+	key := ctx.mapKey(m, mu.Key.Encode(ctx))
+	value := ctx.rawValue(mu.Value.Encode(ctx))
+	values := ctx.mapValuesMemory[m.t].Select(m.addr).(z3.Array)
+	present := ctx.mapPresentMemory[m.t].Select(m.addr).(z3.Array)
+	l := ctx.mapLenMemory[m.t].Select(m.addr).(z3.Int)
+	ctx.mapLenMemory[m.t] = ctx.mapLenMemory[m.t].Store(m.addr,
+		present.Select(key).(z3.Bool).IfThenElse(l, l.Add(ctx.FromInt(1, ctx.IntSort()).(z3.Int))))
+	ctx.mapValuesMemory[m.t] = ctx.mapValuesMemory[m.t].Store(m.addr, values.Store(key, value))
+	ctx.mapPresentMemory[m.t] = ctx.mapPresentMemory[m.t].Store(m.addr, present.Store(key, ctx.FromBool(true)))
+	if mu.Ok == nil {
+		return m.addr.NE(ctx.nilAddr)
+	}
+	mu.Ok.makeFresh(ctx)
+	return mu.Ok.Encode(ctx).(z3.Bool).Eq(m.addr.NE(ctx.nilAddr))
+}
+
+func (mu MapUpdate) ScanVars(vars map[string]Var) {
+	mu.Map.ScanVars(vars)
+	mu.Key.ScanVars(vars)
+	mu.Value.ScanVars(vars)
+	if mu.Ok != nil {
+		mu.Ok.ScanVars(vars)
+	}
+}
+
+func (l Lookup) String() string {
+	if l.CommaOk {
+		return fmt.Sprintf("%s == %s[%s],ok", l.Result, l.Map, l.Key)
+	}
+	return fmt.Sprintf("%s == %s[%s]", l.Result, l.Map, l.Key)
+}
+
+// Encode gives zero value if key is not present.
+func (l Lookup) Encode(ctx *EncodingContext) SymValue {
+	l.Result.makeFresh(ctx)
+	m := l.Map.Encode(ctx).(*SymMap)
+	key := ctx.mapKey(m, l.Key.Encode(ctx))
+	elemT := l.Map.Type.Underlying().(*types.Map).Elem()
+	zero := ctx.zeroValue(elemT)
+	if zero == nil {
+		panic(unsupported("map", "with values of type '%s'", elemT))
+	}
+	present := ctx.mapPresentMemory[m.t].Select(m.addr).(z3.Array).Select(key).(z3.Bool)
+	value := present.IfThenElse(ctx.mapValuesMemory[m.t].Select(m.addr).(z3.Array).Select(key), zero)
+	res := l.Result.Encode(ctx)
+	if l.CommaOk {
+		tuple := res.(*Tuple)
+		return ctx.rawEq(ctx.rawValue(tuple.elems[0]), value).And(tuple.elems[1].(z3.Bool).Eq(present))
+	}
+	return ctx.rawEq(ctx.rawValue(res), value)
+}
+
+func (l Lookup) ScanVars(vars map[string]Var) {
+	l.Result.ScanVars(vars)
+	l.Map.ScanVars(vars)
+	l.Key.ScanVars(vars)
+}
+
+func (mi MakeInterface) String() string {
+	return fmt.Sprintf("%s = box %s", mi.Result, mi.X)
+}
+
+// Encode gives non-nil error for errors, they are encoded as booleans.
+func (mi MakeInterface) Encode(ctx *EncodingContext) SymValue {
+	mi.Result.makeFresh(ctx)
+	if isErrorType(mi.Result.Type) {
+		return mi.Result.Encode(ctx).(z3.Bool)
+	}
+	res := mi.Result.Encode(ctx).(*Interface)
+	// strings can't be stored in memory, only their type is known
+	if _, ok := mi.X.Encode(ctx).(*String); !ok {
+		box := ctx.box(mi.X.Type)
+		ctx.valuesMemory[box] = ctx.valuesMemory[box].Store(res.addr, ctx.rawValue(mi.X.Encode(ctx)))
+	}
+	return ctx.allocate(res.addr).And(ctx.dynamicType(res.addr).Eq(ctx.typeID(mi.X.Type)))
+}
+
+func (mi MakeInterface) ScanVars(vars map[string]Var) {
+	mi.Result.ScanVars(vars)
+	mi.X.ScanVars(vars)
+}
+
+func (ta TypeAssert) String() string {
+	if ta.CommaOk {
+		return fmt.Sprintf("%s == %s.(%s),ok", ta.Result, ta.X, ta.AssertedType)
+	}
+	return fmt.Sprintf("%s == %s.(%s)", ta.Result, ta.X, ta.AssertedType)
+}
+
+// Encode keeps the same box for assertion to interface type.
+func (ta TypeAssert) Encode(ctx *EncodingContext) SymValue {
+	ta.Result.makeFresh(ctx)
+	x := ta.X.Encode(ctx).(*Interface)
+	res := ta.Result.Encode(ctx)
+	value := res
+	if ta.CommaOk {
+		value = res.(*Tuple).elems[0]
+	}
+	var ok, matches, isZero z3.Bool
+	if iface, isIface := ta.AssertedType.Underlying().(*types.Interface); isIface {
+		ok = x.addr.NE(ctx.nilAddr).And(ctx.implements(x, iface))
+		matches = ctx.rawEq(ctx.rawValue(value), x.addr)
+		isZero = ctx.rawEq(ctx.rawValue(value), ctx.nilAddr)
+	} else {
+		ok = ctx.dynamicType(x.addr).Eq(ctx.typeID(ta.AssertedType))
+		v, input := ctx.unbox(x, ta.AssertedType)
+		matches = ctx.rawEq(ctx.rawValue(value), v).And(input)
+		if ta.CommaOk {
+			zero := ctx.zeroValue(ta.AssertedType)
+			if zero == nil {
+				panic(unsupported("type assertion", "to '%s'", ta.AssertedType))
+			}
+			isZero = ctx.rawEq(ctx.rawValue(value), zero)
+		}
+	}
+	switch {
+	case ta.CommaOk:
+		return res.(*Tuple).elems[1].(z3.Bool).Eq(ok).And(ok.Implies(matches), ok.Not().Implies(isZero))
+	case ta.Ok != nil:
+		ta.Ok.makeFresh(ctx)
+		return ta.Ok.Encode(ctx).(z3.Bool).Eq(ok).And(ok.Implies(matches))
+	}
+	return ok.And(matches)
+}
+
+func (ta TypeAssert) ScanVars(vars map[string]Var) {
+	ta.Result.ScanVars(vars)
+	ta.X.ScanVars(vars)
+	if ta.Ok != nil {
+		ta.Ok.ScanVars(vars)
+	}
+}
+
+func (mc MakeClosure) String() string {
+	var s []string
+	for _, b := range mc.Bindings {
+		s = append(s, b.String())
+	}
+	return fmt.Sprintf("%s = closure %s[%s]", mc.Result, mc.Fn, strings.Join(s, ", "))
+}
+
+func (mc MakeClosure) Encode(ctx *EncodingContext) SymValue {
+	mc.Result.makeFresh(ctx)
+	c := mc.Result.Encode(ctx).(*Closure)
+	for i, b := range mc.Bindings {
+		name := ctx.bindings(mc.Fn, i, b.Type)
+		ctx.closureMemory[name] = ctx.closureMemory[name].Store(c.addr, ctx.rawValue(b.Encode(ctx)))
+	}
+	return ctx.allocate(c.addr).And(ctx.target(c.addr).Eq(ctx.funcID(mc.Fn)))
+}
+
+func (mc MakeClosure) ScanVars(vars map[string]Var) {
+	mc.Result.ScanVars(vars)
+	for _, b := range mc.Bindings {
+		b.ScanVars(vars)
+	}
+}
+
+func (d Dispatch) String() string {
+	var s []string
+	for _, fv := range d.FreeVars {
+		s = append(s, fv.String())
+	}
+	return fmt.Sprintf("%s -> %s[%s]", d.Func, d.Fn, strings.Join(s, ", "))
+}
+
+// Encode makes calls of nil function values infeasible, they panic in Go.
+func (d Dispatch) Encode(ctx *EncodingContext) SymValue {
+	f := d.Func.Encode(ctx).(*Closure)
+	if d.Fn == "" {
+		return f.addr.NE(ctx.nilAddr).And(ctx.target(f.addr).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+	}
+	res := ctx.target(f.addr).Eq(ctx.funcID(d.Fn))
+	for i, fv := range d.FreeVars {
+		fv.makeFresh(ctx)
+		name := ctx.bindings(d.Fn, i, fv.Type)
+		res = res.And(ctx.rawEq(ctx.rawValue(fv.Encode(ctx)), ctx.closureMemory[name].Select(f.addr)))
+	}
+	return res
+}
+
+func (d Dispatch) ScanVars(vars map[string]Var) {
+	d.Func.ScanVars(vars)
+	for _, fv := range d.FreeVars {
+		fv.ScanVars(vars)
+	}
+}
+
+func (uc UnknownCall) String() string {
+	return fmt.Sprintf("%s = unknown call of %s", uc.Result, uc.Func)
+}
+
+// Encode records results of call for the function value, tests return them from input functions.
+func (uc UnknownCall) Encode(ctx *EncodingContext) SymValue {
+	f := uc.Func.Encode(ctx).(*Closure)
+	uc.Result.makeFresh(ctx)
+	res := uc.Result.Encode(ctx)
+	results := []SymValue{res}
+	resultTypes := []types.Type{uc.Result.Type}
+	if tuple, ok := res.(*Tuple); ok {
+		t := uc.Result.Type.(*types.Tuple)
+		results, resultTypes = tuple.elems, nil
+		for i := 0; i < t.Len(); i++ {
+			resultTypes = append(resultTypes, t.At(i).Type())
+		}
+	}
+	ctx.unknownCalls = append(ctx.unknownCalls, unknownCall{addr: f.addr, results: results, types: resultTypes})
+	known := ctx.FromBool(true)
+	for i, r := range results {
+		known = known.And(ctx.unknownResult(r, resultTypes[i]))
+	}
+	return known
+}
+
+func (uc UnknownCall) ScanVars(vars map[string]Var) {
+	uc.Result.ScanVars(vars)
+	uc.Func.ScanVars(vars)
+}
+
+func (n NextEntry) String() string {
+	return fmt.Sprintf("%s == next #%d of %s (%s)", n.Result, n.Index, n.Map, n.Key)
+}
+
+// Encode leaves key and value unconstrained if iteration stops.
+func (n NextEntry) Encode(ctx *EncodingContext) SymValue {
+	n.Result.makeFresh(ctx)
+	n.Key.makeFresh(ctx)
+	m := n.Map.Encode(ctx).(*SymMap)
+	key := ctx.mapKey(m, n.Key.Encode(ctx))
+	tuple := n.Result.Encode(ctx).(*Tuple)
+	t := n.Result.Type.(*types.Tuple)
+	ok := ctx.FromInt(int64(n.Index), ctx.IntSort()).(z3.Int).LT(ctx.mapLenMemory[m.t].Select(m.addr).(z3.Int))
+	entry := ctx.mapPresentMemory[m.t].Select(m.addr).(z3.Array).Select(key).(z3.Bool)
+	for _, prev := range n.Prev {
+		entry = entry.And(ctx.rawEq(key, ctx.rawValue(prev.Encode(ctx))).Not())
+	}
+	if !isUnused(t.At(1).Type()) {
+		entry = entry.And(ctx.rawEq(ctx.rawValue(tuple.elems[1]), key))
+	}
+	if !isUnused(t.At(2).Type()) {
+		value := ctx.mapValuesMemory[m.t].Select(m.addr).(z3.Array).Select(key)
+		entry = entry.And(ctx.rawEq(ctx.rawValue(tuple.elems[2]), value))
+	}
+	return tuple.elems[0].(z3.Bool).Eq(ok).And(ok.Implies(entry))
+}
+
+func (n NextEntry) ScanVars(vars map[string]Var) {
+	n.Result.ScanVars(vars)
+	n.Map.ScanVars(vars)
+	n.Key.ScanVars(vars)
+	for _, prev := range n.Prev {
+		prev.ScanVars(vars)
+	}
+}
+
+func (n NextRune) String() string {
+	return fmt.Sprintf("%s == next rune of %s at %s", n.Result, n.Str, n.Pos)
+}
+
+func (n NextRune) Encode(ctx *EncodingContext) SymValue {
+	n.Result.makeFresh(ctx)
+	n.NextPos.makeFresh(ctx)
+	str := n.Str.Encode(ctx).(*String)
+	pos := ctx.toInt(n.Pos.Encode(ctx), n.Pos.Type)
+	tuple := n.Result.Encode(ctx).(*Tuple)
+	t := n.Result.Type.(*types.Tuple)
+	ok := pos.LT(str.len)
+	r, width := ctx.decodeRune(str, pos)
+	step := ctx.toInt(n.NextPos.Encode(ctx), n.NextPos.Type).Eq(pos.Add(width))
+	if !isUnused(t.At(1).Type()) {
+		step = step.And(ctx.toInt(tuple.elems[1], t.At(1).Type()).Eq(pos))
+	}
+	if !isUnused(t.At(2).Type()) {
+		step = step.And(ctx.toInt(tuple.elems[2], t.At(2).Type()).Eq(r))
+	}
+	return tuple.elems[0].(z3.Bool).Eq(ok).And(ok.Implies(step))
+}
+
+func (n NextRune) ScanVars(vars map[string]Var) {
+	n.Result.ScanVars(vars)
+	n.Str.ScanVars(vars)
+	n.Pos.ScanVars(vars)
+	n.NextPos.ScanVars(vars)
+}
+
+func (mc MakeChan) String() string {
+	return fmt.Sprintf("%s = make chan #%d", mc.Result, mc.ID)
+}
+
+func (mc MakeChan) Encode(ctx *EncodingContext) SymValue {
+	mc.Result.makeFresh(ctx)
+	return mc.Result.Encode(ctx).(z3.Int).Eq(ctx.FromInt(int64(mc.ID), ctx.IntSort()).(z3.Int))
+}
+
+func (mc MakeChan) ScanVars(vars map[string]Var) {
+	mc.Result.ScanVars(vars)
+}
+
+func (ci ChanIs) String() string {
+	return fmt.Sprintf("%s is chan #%d", ci.Chan, ci.ID)
+}
+
+func (ci ChanIs) Encode(ctx *EncodingContext) SymValue {
+	return ci.Chan.Encode(ctx).(z3.Int).Eq(ctx.FromInt(int64(ci.ID), ctx.IntSort()).(z3.Int))
+}
+
+func (ci ChanIs) ScanVars(vars map[string]Var) {
+	ci.Chan.ScanVars(vars)
+}
+
+func (r Receive) String() string {
+	value := "closed"
+	if r.Value != nil {
+		value = r.Value.String()
+	}
+	if r.CommaOk {
+		return fmt.Sprintf("%s == <-%s,ok", r.Result, value)
+	}
+	return fmt.Sprintf("%s == <-%s", r.Result, value)
+}
+
+func (r Receive) Encode(ctx *EncodingContext) SymValue {
+	r.Result.makeFresh(ctx)
+	res := r.Result.Encode(ctx)
+	value, t := res, r.Result.Type
+	if r.CommaOk {
+		value, t = res.(*Tuple).elems[0], r.Result.Type.(*types.Tuple).At(0).Type()
+	}
+	var eq z3.Bool
+	if r.Value != nil {
+		eq = ctx.eq(value, r.Value.Encode(ctx))
+	} else {
+		eq = ctx.isZero(value, t)
+	}
+	if r.CommaOk {
+		return eq.And(res.(*Tuple).elems[1].(z3.Bool).Eq(ctx.FromBool(r.Value != nil)))
+	}
+	return eq
+}
+
+func (r Receive) ScanVars(vars map[string]Var) {
+	r.Result.ScanVars(vars)
+	if r.Value != nil {
+		r.Value.ScanVars(vars)
+	}
+}
+
+func (sc SelectCase) String() string {
+	value := "closed"
+	if sc.Value != nil {
+		value = sc.Value.String()
+	}
+	if sc.Recv < 0 {
+		return fmt.Sprintf("%s = select #%d", sc.Result, sc.Index)
+	}
+	return fmt.Sprintf("%s = select #%d <-%s", sc.Result, sc.Index, value)
+}
+
+// Encode leaves values of receive cases that were not selected unconstrained.
+func (sc SelectCase) Encode(ctx *EncodingContext) SymValue {
+	sc.Result.makeFresh(ctx)
+	tuple := sc.Result.Encode(ctx).(*Tuple)
+	res := ctx.toInt(tuple.elems[0], sc.Result.Type.(*types.Tuple).At(0).Type()).Eq(ctx.FromInt(int64(sc.Index), ctx.IntSort()).(z3.Int))
+	res = res.And(tuple.elems[1].(z3.Bool).Eq(ctx.FromBool(sc.Recv >= 0 && sc.Value != nil)))
+	if sc.Recv < 0 {
+		return res
+	}
+	value := tuple.elems[2+sc.Recv]
+	if sc.Value != nil {
+		return res.And(ctx.eq(value, sc.Value.Encode(ctx)))
+	}
+	return res.And(ctx.isZero(value, sc.Result.Type.(*types.Tuple).At(2+sc.Recv).Type()))
+}
+
+func (sc SelectCase) ScanVars(vars map[string]Var) {
+	sc.Result.ScanVars(vars)
+	if sc.Value != nil {
+		sc.Value.ScanVars(vars)
+	}
+}
+
 func toYaml(f Formula) string {
 	d, err := yaml.Marshal(&f)
 	if err != nil {
@@ -1,10 +1,15 @@
 package symexec
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/types"
+	"io"
+	"io/fs"
 	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,81 +19,409 @@ import (
 
 type Testcase struct {
 	model *z3.Model
+	// path is the order in which blocks of analyzed function were executed.
+	path []int
+	// maps are contents of map parameters, they can't be read from model directly.
+	maps map[string]inputMap
+	// strings are values of string parameters and results.
+	strings map[string]string
+	// arrays are elements of array parameters and results, see arrayValues.
+	arrays map[string]string
+	// ifaces are dynamic types of interface parameters, nil if parameter is nil.
+	ifaces map[string]types.Type
+	// boxes are values of interface parameters of basic dynamic types, values of other types are not rendered.
+	boxes map[string]string
+	// funcs are results of calls of function parameters and nil function parameters, see initFunc.
+	funcs map[string]string
+	// panics is set if path ends with panic that is not recovered, there are no results.
+	panics bool
+	// deadlock is set if path ends with all goroutines blocked, there are no results.
+	deadlock bool
+	// goPanic is set with panics if the panic is in goroutine other than main, it crashes the program.
+	goPanic bool
 }
 
-func GenerateTests(filename string, functionTestcases map[*ssa.Function][]Testcase) {
-	filenameWithoutExt, _ := strings.CutSuffix(filename, ".go")
-	fmt.Println(":: generating tests")
-	f, err := os.Create(filenameWithoutExt + "_test.go")
+// Deadlock tells if path ends with all goroutines blocked, such paths have no tests.
+func (tc Testcase) Deadlock() bool {
+	return tc.deadlock
+}
+
+// GoPanic tells if path ends with panic in goroutine other than main, such paths have no tests.
+func (tc Testcase) GoPanic() bool {
+	return tc.goPanic
+}
+
+// inputMap has entries for keys used by analyzed code, values are in model format.
+type inputMap struct {
+	isNil   bool
+	entries []mapEntry
+}
+
+type mapEntry struct {
+	key   string
+	value string
+}
+
+func (m inputMap) String() string {
+	if m.isNil {
+		return "nil"
+	}
+	var entries []string
+	for _, e := range m.entries {
+		entries = append(entries, e.key+": "+e.value)
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// generatedHeader marks files that can be overwritten, see https://go.dev/s/generatedcode.
+const generatedHeader = "// Code generated by gobber. DO NOT EDIT."
+
+// GenerateOptions control how generated tests are written. Tests are always written next to source files,
+// they are in the package they test and call unexported functions, so they don't compile elsewhere.
+type GenerateOptions struct {
+	// DryRun prints generated files to Out instead of writing them.
+	DryRun bool
+	// Diff prints unified diff between existing and generated files to Out instead of writing them,
+	// it can't be set with DryRun.
+	Diff bool
+	// Force allows overwriting files that were not generated by gobber.
+	Force bool
+	// Out receives output of DryRun and Diff, os.Stdout if nil.
+	Out io.Writer
+	// Style is how testcases are grouped into test functions.
+	Style TestStyle
+}
+
+type TestStyle int
+
+const (
+	// StyleFunctions generates separate test function for every testcase.
+	StyleFunctions TestStyle = iota
+	// StyleSubtests generates single test function for every analyzed function, testcases are subtests.
+	StyleSubtests
+)
+
+func (s TestStyle) String() string {
+	switch s {
+	case StyleFunctions:
+		return "functions"
+	case StyleSubtests:
+		return "subtests"
+	default:
+		return fmt.Sprintf("TestStyle(%d)", int(s))
+	}
+}
+
+// ParseTestStyle is the inverse of TestStyle.String.
+func ParseTestStyle(s string) (TestStyle, error) {
+	for _, style := range []TestStyle{StyleFunctions, StyleSubtests} {
+		if style.String() == s {
+			return style, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown test style '%s'", s)
+}
+
+func (s *TestStyle) UnmarshalText(text []byte) error {
+	style, err := ParseTestStyle(string(text))
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
+	}
+	*s = style
+	return nil
+}
+
+// GenerateTests writes testcases of every function into '<file>_test.go', one for each source file.
+// Existing files are only overwritten if they were generated before, unless Force is set.
+func GenerateTests(pkg *PackageResult, opts GenerateOptions) error {
+	if opts.DryRun && opts.Diff {
+		return errors.New("dry run and diff can't be used together")
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
 	}
-	defer f.Close()
+	var errs []error
+	for _, filename := range sourceFiles(pkg) {
+		content, err := renderTestFile(filename, pkg, opts.Style)
+		if err != nil {
+			// partially rendered file is not written
+			errs = append(errs, err)
+			continue
+		}
+		if err := writeTestFile(testFilename(filename), content, opts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func writeTestFile(filename string, content []byte, opts GenerateOptions) error {
+	old, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+	switch {
+	case opts.DryRun:
+		fmt.Fprintf(opts.Out, "// %s\n", filename)
+		_, err := opts.Out.Write(content)
+		return err
+	case opts.Diff:
+		_, err := io.WriteString(opts.Out, unifiedDiff(filename, string(old), string(content)))
+		return err
+	}
+	if exists && !opts.Force && !isGenerated(old) {
+		return fmt.Errorf("'%s' already exists and was not generated by gobber, not overwriting", filename)
+	}
+	return os.WriteFile(filename, content, 0o644)
+}
+
+func isGenerated(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+		if strings.TrimSpace(line) == generatedHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceFiles lists files that declare analyzed functions.
+func sourceFiles(pkg *PackageResult) []string {
+	var filenames []string
+	for _, fr := range pkg.Functions {
+		filename := sourceFile(fr.Function)
+		if filename != "" && !slices.Contains(filenames, filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+	return filenames
+}
+
+func sourceFile(fn *ssa.Function) string {
+	return fn.Prog.Fset.Position(fn.Pos()).Filename
+}
+
+func testFilename(filename string) string {
+	filenameWithoutExt, _ := strings.CutSuffix(filename, ".go")
+	return filenameWithoutExt + "_test.go"
+}
+
+func renderTestFile(filename string, pkg *PackageResult, style TestStyle) ([]byte, error) {
+	f := &bytes.Buffer{}
 	prelude := `
-package main
+%s
+
+package %s
 
 import (
+	"errors"
 	"math"
+	"math/cmplx"
+	"reflect"
 	"testing"
 )
 
 var (
 	_ = testing.Main
+	_ = errors.New
 	_ = math.Abs
+	_ = cmplx.Abs
+	_ = reflect.DeepEqual
 )
 `
-	f.WriteString(strings.Trim(prelude, "\n"))
+	f.WriteString(fmt.Sprintf(strings.Trim(prelude, "\n"), generatedHeader, pkg.Package.Pkg.Name()))
 	f.WriteString("\n\n")
-	for fn, testcases := range functionTestcases {
-		for i, tc := range testcases {
-			vars := parseVars(tc.model)
-			args, err := initArgs(fn, vars)
+	var errs []error
+	for _, fr := range pkg.Functions {
+		fn := fr.Function
+		if sourceFile(fn) != filename {
+			continue
+		}
+		if style == StyleSubtests {
+			code, err := renderSubtests(fn, fr.Testcases)
 			if err != nil {
-				fmt.Println("[ERROR]", err)
+				errs = append(errs, err)
+			}
+			f.WriteString(code)
+			continue
+		}
+		for i, tc := range fr.Testcases {
+			if tc.deadlock || tc.goPanic {
 				continue
 			}
-			name := functionName(fn)
-			f.WriteString(fmt.Sprintf("func Test_%s_%d(t *testing.T) {\n", name, i+1))
-			results := fn.Signature.Results()
-			if results != nil && results.Len() == 1 {
-				resultT := results.At(0).Type()
-				want, err := parseResult(resultT, vars)
-				if err != nil {
-					fmt.Println("[ERROR]", err)
-					continue
-				}
-				for _, code := range args {
-					f.WriteString(fmt.Sprintf("\t%s\n", strings.ReplaceAll(code, "\n", "\n\t")))
-				}
-				var argsNames []string
-				for _, param := range fn.Params {
-					argsNames = append(argsNames, param.Name())
-				}
-				cmp := cmp(resultT)
-				if fn.Signature.Recv() == nil {
-					// functions
-					argsStr := strings.Join(argsNames, ", ")
-					call := fmt.Sprintf("%s(%s)", name, argsStr)
-					f.WriteString(fmt.Sprintf("\tgot := %s\n", call))
-					f.WriteString(fmt.Sprintf("\t%s\n", strings.ReplaceAll(want, "\n", "\n\t")))
-					f.WriteString(fmt.Sprintf("\tif %s {\n", cmp))
-					f.WriteString(fmt.Sprintf("\t\tt.Errorf(\"%s = %%v; want %%v\", got, want)\n", call))
-					f.WriteString("\t}\n")
-				} else {
-					// methods
-					argsStr := strings.Join(argsNames[1:], ", ")
-					call := fmt.Sprintf("%s.%s(%s)", argsNames[0], name, argsStr)
-					f.WriteString(fmt.Sprintf("\tgot := %s\n", call))
-					f.WriteString(fmt.Sprintf("\t%s\n", strings.ReplaceAll(want, "\n", "\n\t")))
-					f.WriteString(fmt.Sprintf("\tif %s {\n", cmp))
-					f.WriteString(fmt.Sprintf("\t\tt.Errorf(\"%s = %%v; want %%v\", got, want)\n", call))
-					f.WriteString("\t}\n")
-				}
+			code, err := renderTestcase(fn, i, tc)
+			if err != nil {
+				errs = append(errs, err)
+				continue
 			}
-			f.WriteString("}\n\n")
+			f.WriteString(code)
+		}
+	}
+	return f.Bytes(), errors.Join(errs...)
+}
+
+// renderSubtests renders all testcases of function as subtests named '1', '2', etc.
+func renderSubtests(fn *ssa.Function, testcases []Testcase) (string, error) {
+	if len(testcases) == 0 {
+		return "", nil
+	}
+	f := &strings.Builder{}
+	f.WriteString(fmt.Sprintf("func %s(t *testing.T) {\n", subtestsName(fn)))
+	var errs []error
+	for i, tc := range testcases {
+		if tc.deadlock || tc.goPanic {
+			continue
+		}
+		body, err := renderTestBody(fn, testcaseVars(tc), tc.maps, tc.ifaces, tc.panics)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.WriteString(fmt.Sprintf("\tt.Run(\"%d\", func(t *testing.T) {\n", i+1))
+		f.WriteString(indent(body))
+		f.WriteString("\t})\n")
+	}
+	f.WriteString("}\n\n")
+	return f.String(), errors.Join(errs...)
+}
+
+func indent(code string) string {
+	lines := splitLines(code)
+	for i, line := range lines {
+		lines[i] = "\t" + line
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// testName is unique for every testcase in package.
+func testName(fn *ssa.Function, i int) string {
+	return fmt.Sprintf("%s_%d", subtestsName(fn), i+1)
+}
+
+// subtestsName is the name of test function with all testcases of fn as subtests.
+func subtestsName(fn *ssa.Function) string {
+	return "Test_" + strings.ReplaceAll(FunctionName(fn), ".", "_")
+}
+
+// subtestName is the full name of subtest of testcase, like in 'go test -run'.
+func subtestName(fn *ssa.Function, i int) string {
+	return fmt.Sprintf("%s/%d", subtestsName(fn), i+1)
+}
+
+func renderTestcase(fn *ssa.Function, i int, tc Testcase) (string, error) {
+	body, err := renderTestBody(fn, testcaseVars(tc), tc.maps, tc.ifaces, tc.panics)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("func %s(t *testing.T) {\n%s}\n\n", testName(fn, i), body), nil
+}
+
+// renderTestBody renders statements of test function from model variables, input maps and interfaces, indented with single tab.
+// Function is expected to panic instead of returning results if panics is set.
+func renderTestBody(fn *ssa.Function, vars map[string]string, maps map[string]inputMap, ifaces map[string]types.Type, panics bool) (string, error) {
+	args, err := initArgs(fn, vars, maps, ifaces)
+	if err != nil {
+		return "", err
+	}
+	name := functionName(fn)
+	f := &strings.Builder{}
+	var argsNames []string
+	for _, param := range fn.Params {
+		argsNames = append(argsNames, param.Name())
+		f.WriteString(fmt.Sprintf("\t%s\n", strings.ReplaceAll(args[param.Name()], "\n", "\n\t")))
+	}
+	var call string
+	if fn.Signature.Recv() == nil {
+		// functions
+		argsStr := strings.Join(argsNames, ", ")
+		call = fmt.Sprintf("%s(%s)", name, argsStr)
+	} else {
+		// methods
+		argsStr := strings.Join(argsNames[1:], ", ")
+		call = fmt.Sprintf("%s.%s(%s)", argsNames[0], name, argsStr)
+	}
+	if panics {
+		f.WriteString("\tdefer func() {\n")
+		f.WriteString("\t\tif recover() == nil {\n")
+		f.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"%s did not panic\")\n", call))
+		f.WriteString("\t\t}\n")
+		f.WriteString("\t}()\n")
+		f.WriteString(fmt.Sprintf("\t%s\n", call))
+		return f.String(), nil
+	}
+	results := fn.Signature.Results()
+	n := results.Len()
+	if n == 0 {
+		f.WriteString(fmt.Sprintf("\t%s\n", call))
+		return f.String(), nil
+	}
+	var gots []string
+	checked := false
+	for i := 0; i < n; i++ {
+		if hasPointers(results.At(i).Type()) {
+			// pointers are not checked, expected objects are unknown and new objects are never identical to them
+			gots = append(gots, "_")
+			continue
+		}
+		gots = append(gots, resultName("got", i, n))
+		checked = true
+	}
+	if !checked {
+		f.WriteString(fmt.Sprintf("\t%s\n", call))
+		return f.String(), nil
+	}
+	f.WriteString(fmt.Sprintf("\t%s := %s\n", strings.Join(gots, ", "), call))
+	for i := 0; i < n; i++ {
+		resultT := results.At(i).Type()
+		if gots[i] == "_" {
+			continue
+		}
+		got, want := gots[i], resultName("want", i, n)
+		what := call
+		if n > 1 {
+			what = fmt.Sprintf("%s result #%d", call, i)
+		}
+		if isErrorType(resultT) {
+			want = resultName("wantErr", i, n)
+			what += " error"
+		}
+		code, err := parseResult(want, resultVar(fn.Signature, i), resultT, vars)
+		if err != nil {
+			return "", err
 		}
+		f.WriteString(fmt.Sprintf("\t%s\n", strings.ReplaceAll(code, "\n", "\n\t")))
+		f.WriteString(fmt.Sprintf("\tif %s {\n", cmp(resultT, got, want)))
+		f.WriteString(fmt.Sprintf("\t\tt.Errorf(\"%s = %%v; want %%v\", %s, %s)\n", what, got, want))
+		f.WriteString("\t}\n")
+	}
+	return f.String(), nil
+}
+
+// hasPointers tells if t is pointer or slice or array of pointers.
+func hasPointers(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return true
+	case *types.Slice:
+		return hasPointers(u.Elem())
+	case *types.Array:
+		return hasPointers(u.Elem())
+	}
+	return false
+}
+
+// resultName is 'got' for single result and 'got0', 'got1', etc. for multiple results.
+func resultName(prefix string, i int, n int) string {
+	if n == 1 {
+		return prefix
 	}
+	return prefix + strconv.Itoa(i)
 }
 
 func functionName(fn *ssa.Function) string {
@@ -107,10 +440,84 @@ func parseVars(model *z3.Model) map[string]string {
 	return vars
 }
 
-func initArgs(fn *ssa.Function, vars map[string]string) (map[string]string, error) {
+// testcaseVars are variables from model, strings are Go string literals, elements of arrays are 'a[i]'.
+func testcaseVars(tc Testcase) map[string]string {
+	vars := parseVars(tc.model)
+	for name, s := range tc.strings {
+		vars[name] = strconv.Quote(s)
+	}
+	for name, elem := range tc.arrays {
+		vars[name] = elem
+	}
+	for name, value := range tc.boxes {
+		vars[name] = value
+	}
+	for name, value := range tc.funcs {
+		vars[name] = value
+	}
+	return vars
+}
+
+func initArgs(fn *ssa.Function, vars map[string]string, maps map[string]inputMap, ifaces map[string]types.Type) (map[string]string, error) {
 	args := make(map[string]string)
 	for _, param := range fn.Params {
 		name := param.Name()
+		if t, ok := param.Type().(*types.Map); ok {
+			code, err := initMap(name, maps[name], t)
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
+		if isInterface(param.Type()) {
+			var boxed string
+			if dynamic := ifaces[name]; dynamic != nil && isBasic(dynamic) {
+				boxed = vars[name]
+			}
+			code, err := initInterface(name, ifaces[name], boxed, param.Type())
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
+		if isFunc(param.Type()) {
+			code, err := initFunc(name, param.Type(), vars)
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
+		if isChan(param.Type()) {
+			args[name] = initChan(name, param.Type())
+			continue
+		}
+		if t, ok := param.Type().Underlying().(*types.Array); ok {
+			code, err := initArray(name, name, param.Type(), t, vars)
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
+		if t, ok := param.Type().Underlying().(*types.Slice); ok {
+			code, err := initSlice(name, name, param.Type(), t, vars)
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
+		if isComplex(param.Type()) {
+			code, err := initComplex(name, name, vars)
+			if err != nil {
+				return nil, err
+			}
+			args[name] = code
+			continue
+		}
 		value := vars[name]
 		code, err := initValue(name, value, param.Type())
 		if err != nil {
@@ -121,12 +528,21 @@ func initArgs(fn *ssa.Function, vars map[string]string) (map[string]string, erro
 	return args, nil
 }
 
-func parseResult(t types.Type, vars map[string]string) (string, error) {
-	value, ok := vars[resultSpecialVar]
+func parseResult(name string, resultVar string, t types.Type, vars map[string]string) (string, error) {
+	switch u := t.Underlying().(type) {
+	case *types.Array:
+		return initArray(name, resultVar, t, u, vars)
+	case *types.Slice:
+		return initSlice(name, resultVar, t, u, vars)
+	}
+	if isComplex(t) {
+		return initComplex(name, resultVar, vars)
+	}
+	value, ok := vars[resultVar]
 	if !ok {
-		return "", fmt.Errorf("result not found in model")
+		return "", fmt.Errorf("result '%s' not found in model", resultVar)
 	}
-	return initValue("want", value, t)
+	return initValue(name, value, t)
 }
 
 func trim(value string) string {
@@ -143,6 +559,10 @@ func trim(value string) string {
 }
 
 func initValue(name string, value string, t types.Type) (string, error) {
+	if isErrorType(t) {
+		// true if error is not nil
+		return initValue(name, value, types.Typ[types.Bool])
+	}
 	switch t := t.(type) {
 	case *types.Basic:
 		switch t.Kind() {
@@ -150,6 +570,8 @@ func initValue(name string, value string, t types.Type) (string, error) {
 			return initInt(name, value, "")
 		case types.Int8, types.Int16, types.Int32, types.Int64:
 			return initInt(name, value, t.Name())
+		case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return initUint(name, value, t.Name())
 		case types.Bool:
 			value := trim(value)
 			var goValue string
@@ -169,80 +591,321 @@ func initValue(name string, value string, t types.Type) (string, error) {
 			} else {
 				return initSmtFloat64(name, value)
 			}
+		case types.Float32:
+			if value == "" {
+				return fmt.Sprintf("%s := float32(0.0)", name), nil
+			} else {
+				return initSmtFloat32(name, value)
+			}
+		case types.String:
+			if value == "" {
+				value = `""`
+			}
+			return fmt.Sprintf("%s := %s", name, value), nil
 		default:
 			return "", fmt.Errorf("unknown basic type '%s'", t)
 		}
+	case *types.Named:
+		if isStruct(t) && value == "" {
+			return fmt.Sprintf("%s := %s{}", name, t.Obj().Name()), nil
+		}
+		u, ok := t.Underlying().(*types.Basic)
+		if !ok {
+			return "", fmt.Errorf("unknown named type '%s'", t)
+		}
+		// value of underlying type is converted to named type
+		code, err := initValue(name+"_v", value, u)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s\n%s := %s(%s_v)", code, name, t.Obj().Name(), name), nil
 	case *types.Pointer:
-		switch t := t.Elem().(type) {
-		case *types.Named:
-			return fmt.Sprintf("%s := &%s{}", name, t.Obj().Name()), nil
-		default:
-			return "", fmt.Errorf("unknown pointer type '%s'", t)
+		// pointers point to zero values
+		if named, ok := t.Elem().(*types.Named); ok && isStruct(named) {
+			return fmt.Sprintf("%s := &%s{}", name, named.Obj().Name()), nil
 		}
+		elem := types.TypeString(t.Elem(), func(*types.Package) string { return "" })
+		return fmt.Sprintf("%s := new(%s)", name, elem), nil
 	default:
 		return "", fmt.Errorf("unknown type '%s'", t)
 	}
 }
 
+// initMap initializes map literal, keys and values are initialized before it.
+// Map that is not used by analyzed code has no entries, it is nil.
+func initMap(name string, m inputMap, t *types.Map) (string, error) {
+	typ := types.TypeString(t, func(*types.Package) string { return "" })
+	if m.isNil || m.entries == nil {
+		return fmt.Sprintf("%s := %s(nil)", name, typ), nil
+	}
+	var lines, entries []string
+	for i, e := range m.entries {
+		key, value := fmt.Sprintf("%s_k%d", name, i), fmt.Sprintf("%s_v%d", name, i)
+		code, err := initValue(key, e.key, t.Key())
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, code)
+		code, err = initValue(value, e.value, t.Elem())
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, code)
+		entries = append(entries, key+": "+value)
+	}
+	lines = append(lines, fmt.Sprintf("%s := %s{%s}", name, typ, strings.Join(entries, ", ")))
+	return strings.Join(lines, "\n"), nil
+}
+
+// initArray initializes array literal of type t from elements of variable v, elements are initialized before it.
+func initArray(name string, v string, t types.Type, u *types.Array, vars map[string]string) (string, error) {
+	var lines, elems []string
+	for i := int64(0); i < u.Len(); i++ {
+		elem, elemVar := fmt.Sprintf("%s_%d", name, i), fmt.Sprintf("%s[%d]", v, i)
+		var code string
+		var err error
+		if inner, ok := u.Elem().Underlying().(*types.Array); ok {
+			code, err = initArray(elem, elemVar, u.Elem(), inner, vars)
+		} else {
+			code, err = initValue(elem, vars[elemVar], u.Elem())
+		}
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, code)
+		elems = append(elems, elem)
+	}
+	typ := types.TypeString(t, func(*types.Package) string { return "" })
+	lines = append(lines, fmt.Sprintf("%s := %s{%s}", name, typ, strings.Join(elems, ", ")))
+	return strings.Join(lines, "\n"), nil
+}
+
+// initSlice initializes slice literal of type t from length and elements of variable v, elements are initialized before it.
+// Slice without length is nil.
+func initSlice(name string, v string, t types.Type, u *types.Slice, vars map[string]string) (string, error) {
+	typ := types.TypeString(t, func(*types.Package) string { return "" })
+	length, ok := vars[fmt.Sprintf("len(%s)", v)]
+	if !ok {
+		return fmt.Sprintf("%s := %s(nil)", name, typ), nil
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		return "", err
+	}
+	var lines, elems []string
+	for i := 0; i < n; i++ {
+		elem, elemVar := fmt.Sprintf("%s_%d", name, i), fmt.Sprintf("%s[%d]", v, i)
+		var code string
+		var err error
+		switch inner := u.Elem().Underlying().(type) {
+		case *types.Slice:
+			code, err = initSlice(elem, elemVar, u.Elem(), inner, vars)
+		case *types.Array:
+			code, err = initArray(elem, elemVar, u.Elem(), inner, vars)
+		default:
+			code, err = initValue(elem, vars[elemVar], u.Elem())
+		}
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, code)
+		elems = append(elems, elem)
+	}
+	lines = append(lines, fmt.Sprintf("%s := %s{%s}", name, typ, strings.Join(elems, ", ")))
+	return strings.Join(lines, "\n"), nil
+}
+
+// initComplex initializes complex128 from real and imaginary parts of variable v, parts are initialized before it.
+func initComplex(name string, v string, vars map[string]string) (string, error) {
+	re, err := initValue(name+"_re", vars[v+".REAL"], types.Typ[types.Float64])
+	if err != nil {
+		return "", err
+	}
+	im, err := initValue(name+"_im", vars[v+".IMAG"], types.Typ[types.Float64])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n%s\n%s := complex(%s_re, %s_im)", re, im, name, name, name), nil
+}
+
+// isComplex tells if underlying type of t is complex128.
+func isComplex(t types.Type) bool {
+	u, ok := t.Underlying().(*types.Basic)
+	return ok && u.Kind() == types.Complex128
+}
+
+// initInterface converts value of dynamic type to interface type t, value is initialized before it.
+// Value in model format is known for basic dynamic types, values of other types are zero.
+func initInterface(name string, dynamic types.Type, boxed string, t types.Type) (string, error) {
+	typ := types.TypeString(t, func(*types.Package) string { return "" })
+	if dynamic == nil {
+		return fmt.Sprintf("%s := %s(nil)", name, typ), nil
+	}
+	value := name + "_v"
+	code, err := initValue(value, boxed, dynamic)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n%s := %s(%s)", code, name, typ, value), nil
+}
+
+// initFunc initializes function that returns results of calls made by analyzed code in order, zero values after them.
+// Results of k-th call are 'f()#k' in vars, 'f()#k.i' for multiple results, their number is 'calls(f)'; nil function is 'nil'.
+func initFunc(name string, t types.Type, vars map[string]string) (string, error) {
+	qualifier := func(*types.Package) string { return "" }
+	if vars[name] == "nil" {
+		return fmt.Sprintf("%s := (%s)(nil)", name, types.TypeString(t, qualifier)), nil
+	}
+	sig := t.Underlying().(*types.Signature)
+	// named results allow empty return
+	var results []*types.Var
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, types.NewVar(0, nil, "_", sig.Results().At(i).Type()))
+	}
+	stub := types.NewSignatureType(nil, nil, nil, sig.Params(), types.NewTuple(results...), sig.Variadic())
+	decl := name + " :="
+	if _, ok := t.(*types.Named); ok {
+		decl = fmt.Sprintf("var %s %s =", name, types.TypeString(t, qualifier))
+	}
+	calls, _ := strconv.Atoi(vars["calls("+name+")"])
+	if calls == 0 || len(results) == 0 {
+		return fmt.Sprintf("%s %s { return }", decl, types.TypeString(stub, qualifier)), nil
+	}
+	var lines, cases []string
+	for k := 1; k <= calls; k++ {
+		var values []string
+		for i := 0; i < len(results); i++ {
+			key := fmt.Sprintf("%s()#%d", name, k)
+			value := fmt.Sprintf("%s_%d", name, k)
+			if len(results) > 1 {
+				key += fmt.Sprintf(".%d", i)
+				value += fmt.Sprintf("_%d", i)
+			}
+			code, err := initResult(value, vars[key], results[i].Type())
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, code)
+			values = append(values, value)
+		}
+		cases = append(cases, fmt.Sprintf("\tcase %d:\n\t\treturn %s", k, strings.Join(values, ", ")))
+	}
+	lines = append(lines,
+		name+"_calls := 0",
+		fmt.Sprintf("%s %s {", decl, types.TypeString(stub, qualifier)),
+		fmt.Sprintf("\t%s_calls++", name),
+		fmt.Sprintf("\tswitch %s_calls {", name),
+	)
+	lines = append(lines, cases...)
+	lines = append(lines, "\t}", "\treturn", "}")
+	return strings.Join(lines, "\n"), nil
+}
+
+// initResult initializes result of input function, errors are not nil if value is true.
+// Results of basic types are known, results of other types are zero.
+func initResult(name string, value string, t types.Type) (string, error) {
+	switch {
+	case isErrorType(t):
+		if trim(value) == "true" {
+			return fmt.Sprintf("%s := errors.New(\"%s\")", name, name), nil
+		}
+		return fmt.Sprintf("var %s error", name), nil
+	case isBasic(t):
+		return initValue(name, value, t)
+	}
+	return fmt.Sprintf("var %s %s", name, types.TypeString(t, func(*types.Package) string { return "" })), nil
+}
+
+// initChan initializes nil channel, analyzed function can't communicate through input channels.
+func initChan(name string, t types.Type) string {
+	return fmt.Sprintf("%s := (%s)(nil)", name, types.TypeString(t, func(*types.Package) string { return "" }))
+}
+
 func initSmtFloat64(name string, value string) (string, error) {
+	return initSmtFloat(name, value, types.Typ[types.Float64])
+}
+
+func initSmtFloat32(name string, value string) (string, error) {
+	return initSmtFloat(name, value, types.Typ[types.Float32])
+}
+
+// initSmtFloat initializes float64 or float32 from SMT floating-point literal, finite values are initialized from their bits.
+func initSmtFloat(name string, value string, t *types.Basic) (string, error) {
 	value = strings.Trim(value, "()")
 	components := strings.Split(value, " ")
 	if len(components) != 4 {
-		return "", fmt.Errorf("expected 4 components for float64: %s", value)
+		return "", fmt.Errorf("expected 4 components for %s: %s", t, value)
 	}
 	if components[0] != "_" {
-		signBin, ok := strings.CutPrefix(components[1], "#b")
-		if !ok {
-			return "", fmt.Errorf("invalid sign for float64: %s", value)
-		}
-		expBin, ok := strings.CutPrefix(components[2], "#b")
-		if !ok {
-			return "", fmt.Errorf("invalid exponent for float64: %s", value)
-		}
-		mantHex, ok := strings.CutPrefix(components[3], "#x")
-		if !ok {
-			return "", fmt.Errorf("invalid mantissa for float64: %s", value)
-		}
-		signExpBin := signBin + expBin
-		hex := ""
-		for i := 0; i < len(signExpBin); i += 4 {
-			v, err := strconv.ParseUint(signExpBin[i:i+4], 2, 4)
+		var bits uint64
+		for i, what := range []string{"sign", "exponent", "mantissa"} {
+			part, size, err := smtBits(components[i+1])
 			if err != nil {
-				return "", fmt.Errorf("error when parsing float64 '%s': %w", value, err)
+				return "", fmt.Errorf("invalid %s for %s '%s': %w", what, t, value, err)
 			}
-			hex += fmt.Sprintf("%x", v)
+			bits = bits<<size | part
 		}
-		hex += mantHex
-		bits, err := strconv.ParseUint(hex, 16, 64)
-		if err != nil {
-			return "", fmt.Errorf("error when parsing float64 '%s': %w", value, err)
+		if t.Kind() == types.Float32 {
+			f32 := math.Float32frombits(uint32(bits))
+			return fmt.Sprintf("%s_bits := uint32(0x%x) // %f\n%s := math.Float32frombits(%s_bits)", name, bits, f32, name, name), nil
 		}
 		f64 := math.Float64frombits(bits)
 		return fmt.Sprintf("%s_bits := uint64(0x%x) // %f\n%s := math.Float64frombits(%s_bits)", name, bits, f64, name, name), nil
 	} else {
+		var goValue string
 		switch components[1] {
 		case "+zero":
-			return fmt.Sprintf("%s := 0.0", name), nil
+			goValue = "0.0"
 		case "-zero":
-			return fmt.Sprintf("%s := 0.0\n%s *= -1.0", name, name), nil
+			return fmt.Sprintf("%s := %s\n%s *= -1.0", name, floatOf(t, "0.0"), name), nil
 		case "NaN":
-			return fmt.Sprintf("%s := math.NaN()", name), nil
+			goValue = "math.NaN()"
 		case "+oo":
-			return fmt.Sprintf("%s := math.Inf(1)", name), nil
+			goValue = "math.Inf(1)"
 		case "-oo":
-			return fmt.Sprintf("%s := math.Inf(-1)", name), nil
+			goValue = "math.Inf(-1)"
 		default:
 			return fmt.Sprintf("// %s := %s", name, value), nil
 		}
+		return fmt.Sprintf("%s := %s", name, floatOf(t, goValue)), nil
+	}
+}
+
+// smtBits parses SMT bit-vector literal in binary or hexadecimal form, size is the number of bits.
+func smtBits(literal string) (bits uint64, size int, err error) {
+	if bin, ok := strings.CutPrefix(literal, "#b"); ok {
+		bits, err = strconv.ParseUint(bin, 2, 64)
+		return bits, len(bin), err
+	}
+	if hex, ok := strings.CutPrefix(literal, "#x"); ok {
+		bits, err = strconv.ParseUint(hex, 16, 64)
+		return bits, 4 * len(hex), err
+	}
+	return 0, 0, fmt.Errorf("expected bit-vector literal, got '%s'", literal)
+}
+
+// floatOf converts float64 expression to type t.
+func floatOf(t *types.Basic, expr string) string {
+	if t.Kind() == types.Float32 {
+		return fmt.Sprintf("float32(%s)", expr)
 	}
+	return expr
 }
 
 func initInt(name string, value string, t string) (string, error) {
 	value = trim(value)
 	var goValue string
-	if value == "" {
+	switch {
+	case value == "":
 		goValue = "0"
-	} else {
+	case strings.HasPrefix(value, "#"):
+		// bit-vectors are in two's complement
+		bits, size, err := smtBits(value)
+		if err != nil {
+			return "", fmt.Errorf("error when parsing integer '%s': %w", value, err)
+		}
+		goValue = fmt.Sprint(int64(bits<<(64-size)) >> (64 - size))
+	default:
 		i, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
 			return "", fmt.Errorf("error when parsing integer '%s': %w", value, err)
@@ -256,13 +919,42 @@ func initInt(name string, value string, t string) (string, error) {
 	}
 }
 
-func cmp(t types.Type) string {
+func initUint(name string, value string, t string) (string, error) {
+	value = trim(value)
+	goValue := "0"
+	if value != "" {
+		var i uint64
+		var err error
+		if strings.HasPrefix(value, "#") {
+			i, _, err = smtBits(value)
+		} else {
+			i, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil {
+			return "", fmt.Errorf("error when parsing unsigned integer '%s': %w", value, err)
+		}
+		goValue = fmt.Sprint(i)
+	}
+	return fmt.Sprintf("%s := %s(%s)", name, t, goValue), nil
+}
+
+func cmp(t types.Type, got string, want string) string {
+	if isErrorType(t) {
+		return fmt.Sprintf("(%s != nil) != %s", got, want)
+	}
+	if _, ok := t.Underlying().(*types.Slice); ok {
+		return fmt.Sprintf("!reflect.DeepEqual(%s, %s)", got, want)
+	}
 	switch t := t.(type) {
 	case *types.Basic:
 		switch t.Kind() {
 		case types.Float64:
-			return "math.Abs(got - want) > 1e-6 && !(math.IsNaN(got) && math.IsNaN(want))"
+			return fmt.Sprintf("math.Abs(%s - %s) > 1e-6 && !(math.IsNaN(%s) && math.IsNaN(%s))", got, want, got, want)
+		case types.Float32:
+			return fmt.Sprintf("math.Abs(float64(%s - %s)) > 1e-6 && !(math.IsNaN(float64(%s)) && math.IsNaN(float64(%s)))", got, want, got, want)
+		case types.Complex128:
+			return fmt.Sprintf("cmplx.Abs(%s - %s) > 1e-6 && !(cmplx.IsNaN(%s) && cmplx.IsNaN(%s))", got, want, got, want)
 		}
 	}
-	return "got != want"
+	return fmt.Sprintf("%s != %s", got, want)
 }
@@ -1,6 +1,12 @@
 package symexec
 
-import "testing"
+import (
+	"go/types"
+	"strconv"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
 
 func TestInitSmtFloat_Normal(t *testing.T) {
 	want := "a_bits := uint64(0x41f4c0012080043d) // 5570040328.001035\na := math.Float64frombits(a_bits)"
@@ -67,3 +73,267 @@ func TestInitSmtFloat_NegInf(t *testing.T) {
 		t.Errorf("got %v; want %v", got, want)
 	}
 }
+
+func TestInitSmtFloat32_Normal(t *testing.T) {
+	want := "a_bits := uint32(0x3fc00000) // 1.500000\na := math.Float32frombits(a_bits)"
+	got, err := initSmtFloat32("a", "(fp #b0 #x7f #b10000000000000000000000)")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestInitSmtFloat32_NegInf(t *testing.T) {
+	want := "a := float32(math.Inf(-1))"
+	got, err := initSmtFloat32("a", "(_ -oo 8 24)")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func methodByName(t *testing.T, filename string, name string) *ssa.Function {
+	pkgs, err := loadPackages("", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range packageFunctions(pkgs[0]) {
+		if FunctionName(fn) == name {
+			return fn
+		}
+	}
+	t.Fatalf("function '%s' not found", name)
+	return nil
+}
+
+func TestRenderTestBody_MultipleResults(t *testing.T) {
+	fn := methodByName(t, "invokes/simpleCalls.go", "InvokeExample.SimpleFormula")
+	vars := map[string]string{"fst": "50", "snd": "0", "$result.0": "0", "$result.1": "true"}
+	got, err := renderTestBody(fn, vars, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `	i := &InvokeExample{}
+	fst := 50
+	snd := 0
+	got0, got1 := i.SimpleFormula(fst, snd)
+	want0 := 0
+	if got0 != want0 {
+		t.Errorf("i.SimpleFormula(fst, snd) result #0 = %v; want %v", got0, want0)
+	}
+	wantErr1 := true
+	if (got1 != nil) != wantErr1 {
+		t.Errorf("i.SimpleFormula(fst, snd) result #1 error = %v; want %v", got1, wantErr1)
+	}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTestBody_MissingResult(t *testing.T) {
+	fn := methodByName(t, "invokes/simpleCalls.go", "InvokeExample.SimpleFormula")
+	if _, err := renderTestBody(fn, map[string]string{"$result.0": "0"}, nil, nil, false); err == nil {
+		t.Error("no error for missing error result")
+	}
+}
+
+func TestRenderTestBody_PointerResult(t *testing.T) {
+	fn := methodByName(t, "invokes/simpleCalls.go", "InvokeExample.GetNullOrValue")
+	got, err := renderTestBody(fn, map[string]string{"$result": "$addr!val!1"}, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `	i := &InvokeExample{}
+	invokeObject := &InvokeClass{}
+	i.GetNullOrValue(invokeObject)
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTestBody_Panics(t *testing.T) {
+	fn := methodByName(t, "invokes/simpleCalls.go", "InvokeExample.SimpleFormula")
+	got, err := renderTestBody(fn, map[string]string{"fst": "1", "snd": "2"}, nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `	i := &InvokeExample{}
+	fst := 1
+	snd := 2
+	defer func() {
+		if recover() == nil {
+			t.Errorf("i.SimpleFormula(fst, snd) did not panic")
+		}
+	}()
+	i.SimpleFormula(fst, snd)
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateTests_DryRunAndDiff(t *testing.T) {
+	if err := GenerateTests(&PackageResult{}, GenerateOptions{DryRun: true, Diff: true}); err == nil {
+		t.Error("no error for dry run with diff")
+	}
+}
+
+func TestInitMap(t *testing.T) {
+	mapT := types.NewMap(types.Typ[types.Int], types.Typ[types.Bool])
+	got, err := initMap("m", inputMap{entries: []mapEntry{{"(- 1)", "true"}, {"2", "false"}}}, mapT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `m_k0 := -1
+m_v0 := true
+m_k1 := 2
+m_v1 := false
+m := map[int]bool{m_k0: m_v0, m_k1: m_v1}`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	for _, m := range []inputMap{{isNil: true}, {}} {
+		if got, _ := initMap("m", m, mapT); got != "m := map[int]bool(nil)" {
+			t.Errorf("got '%s' for %+v; want nil map", got, m)
+		}
+	}
+	if got, _ := initMap("m", inputMap{entries: []mapEntry{}}, mapT); got != "m := map[int]bool{}" {
+		t.Errorf("got '%s'; want empty map", got)
+	}
+}
+
+func TestInitSlice(t *testing.T) {
+	sliceT := types.NewSlice(types.Typ[types.Int])
+	vars := map[string]string{"len(a)": "2", "a[0]": "(- 1)", "a[1]": "3"}
+	got, err := initSlice("a", "a", sliceT, sliceT, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `a_0 := -1
+a_1 := 3
+a := []int{a_0, a_1}`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, _ := initSlice("a", "a", sliceT, sliceT, map[string]string{}); got != "a := []int(nil)" {
+		t.Errorf("got '%s'; want nil slice", got)
+	}
+	if got, _ := initSlice("a", "a", sliceT, sliceT, map[string]string{"len(a)": "0"}); got != "a := []int{}" {
+		t.Errorf("got '%s'; want empty slice", got)
+	}
+}
+
+func TestInitComplex(t *testing.T) {
+	got, err := initComplex("c", "c", map[string]string{"c.REAL": "(fp #b0 #b01111111111 #x0000000000000)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `c_re_bits := uint64(0x3ff0000000000000) // 1.000000
+c_re := math.Float64frombits(c_re_bits)
+c_im := 0.0
+c := complex(c_re, c_im)`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInitInterface(t *testing.T) {
+	pkg := types.NewPackage("example", "main")
+	shape := types.NewNamed(types.NewTypeName(0, pkg, "Shape", nil), types.NewInterfaceType(nil, nil), nil)
+	circle := types.NewNamed(types.NewTypeName(0, pkg, "Circle", nil), types.NewStruct(nil, nil), nil)
+	got, err := initInterface("s", types.NewPointer(circle), "", shape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `s_v := &Circle{}
+s := Shape(s_v)`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, _ := initInterface("s", nil, "", shape); got != "s := Shape(nil)" {
+		t.Errorf("got '%s'; want nil interface", got)
+	}
+}
+
+func TestInitFunc(t *testing.T) {
+	params := types.NewTuple(types.NewVar(0, nil, "x", types.Typ[types.Int]))
+	results := types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.Bool]))
+	sig := types.NewSignatureType(nil, nil, nil, params, results, false)
+	if got, _ := initFunc("f", sig, map[string]string{}); got != "f := func(x int) (_ bool) { return }" {
+		t.Errorf("got '%s'; want stub returning zero values", got)
+	}
+	pred := types.NewNamed(types.NewTypeName(0, types.NewPackage("example", "main"), "Predicate", nil), sig, nil)
+	if got, _ := initFunc("f", pred, map[string]string{}); got != "var f Predicate = func(x int) (_ bool) { return }" {
+		t.Errorf("got '%s'; want stub of named type", got)
+	}
+	if got, _ := initFunc("f", pred, map[string]string{"f": "nil"}); got != "f := (Predicate)(nil)" {
+		t.Errorf("got '%s'; want nil function", got)
+	}
+}
+
+func TestInitFunc_Calls(t *testing.T) {
+	params := types.NewTuple(types.NewVar(0, nil, "x", types.Typ[types.Int]))
+	results := types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.Int]), types.NewVar(0, nil, "", types.Universe.Lookup("error").Type()))
+	sig := types.NewSignatureType(nil, nil, nil, params, results, false)
+	vars := map[string]string{"calls(f)": "2", "f()#1.0": "(- 3)", "f()#1.1": "false", "f()#2.0": "7", "f()#2.1": "true"}
+	got, err := initFunc("f", sig, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `f_1_0 := -3
+var f_1_1 error
+f_2_0 := 7
+f_2_1 := errors.New("f_2_1")
+f_calls := 0
+f := func(x int) (_ int, _ error) {
+	f_calls++
+	switch f_calls {
+	case 1:
+		return f_1_0, f_1_1
+	case 2:
+		return f_2_0, f_2_1
+	}
+	return
+}`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInitChan(t *testing.T) {
+	recv := types.NewChan(types.RecvOnly, types.Typ[types.Int])
+	if got, want := initChan("ch", recv), "ch := (<-chan int)(nil)"; got != want {
+		t.Errorf("got '%s'; want '%s'", got, want)
+	}
+}
+
+func TestInitValue_String(t *testing.T) {
+	if got, _ := initValue("s", strconv.Quote("say \"hi\"\n"), types.Typ[types.String]); got != `s := "say \"hi\"\n"` {
+		t.Errorf("got '%s'", got)
+	}
+	if got, _ := initValue("s", "", types.Typ[types.String]); got != `s := ""` {
+		t.Errorf("got '%s' for missing value", got)
+	}
+}
+
+func TestInitValue_BitVector(t *testing.T) {
+	if got, _ := initValue("a", "#xff", types.Typ[types.Int8]); got != "a := int8(-1)" {
+		t.Errorf("got '%s'", got)
+	}
+	if got, _ := initValue("a", "#xffffffff", types.Typ[types.Uint32]); got != "a := uint32(4294967295)" {
+		t.Errorf("got '%s' for unsigned", got)
+	}
+}
+
+func TestInitValue_Named(t *testing.T) {
+	level := types.NewNamed(types.NewTypeName(0, types.NewPackage("example", "main"), "Level", nil), types.Typ[types.Uint8], nil)
+	if got, _ := initValue("l", "3", level); got != "l_v := uint8(3)\nl := Level(l_v)" {
+		t.Errorf("got '%s'", got)
+	}
+}
@@ -0,0 +1,438 @@
+package symexec
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// isChan tells if values of type t are channels.
+func isChan(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Chan)
+	return ok
+}
+
+// goroutine is a goroutine that is not running, frames are its call stack.
+type goroutine struct {
+	id     int
+	frames []*Frame
+	// started is false until goroutine is scheduled for the first time.
+	started bool
+	// blocked is the channel operation that goroutine waits for, nil if goroutine can run.
+	blocked *chanOp
+}
+
+func (g *goroutine) copy() *goroutine {
+	return &goroutine{
+		id:      g.id,
+		frames:  copyFrames(g.frames),
+		started: g.started,
+		blocked: g.blocked,
+	}
+}
+
+// channel is made by analyzed code, its number is its index in State.channels plus one.
+// Buffer holds values that were sent but not received yet.
+type channel struct {
+	elem     types.Type
+	capacity int
+	buffer   []Var
+	closed   bool
+}
+
+func (c *channel) copy() *channel {
+	var buffer []Var
+	return &channel{
+		elem:     c.elem,
+		capacity: c.capacity,
+		buffer:   append(buffer, c.buffer...),
+		closed:   c.closed,
+	}
+}
+
+// chanOp is a send, a receive or a select, single send or receive is the only case of operation.
+type chanOp struct {
+	cases []chanCase
+	// result of receive or select
+	result   Var
+	commaOk  bool
+	isSelect bool
+	// blocking is false for select with default case.
+	blocking bool
+}
+
+// chanCase is a send of value or a receive on channel with number ch, nil channel is 0.
+type chanCase struct {
+	ch    int
+	send  bool
+	value Var
+	// recv is the index of case among receive cases of select.
+	recv int
+}
+
+// main is the root frame of goroutine that runs analyzed function.
+func (s *State) main() *Frame {
+	if s.goroutine == 0 && s.frames != nil {
+		return s.frames[0]
+	}
+	for _, g := range s.goroutines {
+		if g.id == 0 {
+			return g.frames[0]
+		}
+	}
+	panic("main goroutine not found")
+}
+
+// segment is the part of running goroutine that was executed since it was scheduled.
+func (s *State) segment() Formula {
+	if s.goroutine == 0 {
+		return And{s.frames[0].call.Body}
+	}
+	return s.frames[0].call
+}
+
+// suspend stops running goroutine, it waits for blocked operation if it is set.
+// Exited goroutine is not suspended, only its segment is kept.
+func (s *State) suspend(exited bool, blocked *chanOp) {
+	s.segments = append(s.segments, s.segment())
+	if !exited {
+		s.goroutines = append(s.goroutines, &goroutine{
+			id:      s.goroutine,
+			frames:  s.frames,
+			started: true,
+			blocked: blocked,
+		})
+	}
+	s.frames = nil
+}
+
+// resume runs i-th goroutine that is not running.
+// Frames of goroutine that was already scheduled continue in new dynamic calls, previous calls belong to previous segments.
+func (s *State) resume(i int) {
+	g := s.goroutines[i]
+	s.goroutines = slices.Delete(s.goroutines, i, i+1)
+	s.goroutine, s.frames = g.id, g.frames
+	if !g.started {
+		return
+	}
+	for k, frame := range s.frames {
+		call := &DynamicCall{
+			Result: frame.call.Result,
+			Name:   frame.call.Name,
+		}
+		if k > 0 {
+			s.frames[k-1].push(call)
+		}
+		frame.call = call
+	}
+}
+
+// spawn makes goroutine for go statement, it runs when it is scheduled.
+// Function value and arguments are evaluated by go statement.
+func (a *Analyzer) spawn(state *State, pkg *ssa.Package, common *ssa.CallCommon) {
+	frame := state.currentFrame()
+	callee := common.StaticCallee()
+	if common.IsInvoke() || callee == nil {
+		panic(unsupported("go statement", "calling '%s'", common.Value))
+	}
+	name := removeArgs(common.String())
+	if mock := a.mock(callee, frame.function, pkg); mock != nil {
+		callee, name = mock, mock.Name()
+	}
+	if callee.Blocks == nil {
+		panic(unsupported("external call", "%s", callee))
+	}
+	values := common.Args
+	if mc, ok := common.Value.(*ssa.MakeClosure); ok && callee == mc.Fn {
+		values = append(slices.Clone(mc.Bindings), values...)
+	}
+	state.nextGoroutineId++
+	k := state.nextGoroutineId
+	var args []Var
+	for i, v := range values {
+		tmp := frame.newVar(&TempRegister{t: v.Type(), name: fmt.Sprintf("go%d.%d", k, i)})
+		frame.push(Convert{
+			Result: tmp,
+			Arg:    frame.newVar(v),
+		})
+		args = append(args, tmp)
+	}
+	result := frame.newVar(&TempRegister{t: common.Signature().Results(), name: fmt.Sprintf("go%d", k)})
+	if sig := common.Signature(); sig.Results().Len() == 1 {
+		result.Type = sig.Results().At(0).Type()
+	}
+	state.nextFrameId++
+	root := &Frame{id: state.nextFrameId, function: callee}
+	root.call = &DynamicCall{
+		Result: result,
+		Name:   name,
+		Args:   args,
+		Params: root.params(),
+	}
+	state.goroutines = append(state.goroutines, &goroutine{id: k, frames: []*Frame{root}})
+}
+
+// yield queues state for running goroutine and for every goroutine that can preempt it.
+func (a *Analyzer) yield(queue Queue, state *State) {
+	if state.preemptions < a.current.preemptions {
+		for i, g := range state.goroutines {
+			if g.blocked != nil {
+				continue
+			}
+			next := state.copy()
+			next.preemptions++
+			next.suspend(false, nil)
+			next.resume(i)
+			queue.push(next)
+		}
+	}
+	queue.push(state)
+}
+
+// schedule queues state for every goroutine that can run after running goroutine blocked or exited.
+// Testcase is returned if all goroutines are blocked.
+func (a *Analyzer) schedule(queue Queue, state *State, fn *ssa.Function) (Testcase, solverStatus) {
+	deadlock := true
+	for i, g := range state.goroutines {
+		if g.blocked != nil {
+			continue
+		}
+		deadlock = false
+		next := state.copy()
+		next.resume(i)
+		queue.push(next)
+	}
+	if !deadlock {
+		return Testcase{}, statusUnsat
+	}
+	main := state.main()
+	tc, status := a.solve(fn, state.formula())
+	switch status {
+	case statusSat:
+		a.logger.Debug("found deadlock", "path", fmt.Sprint(main.blockOrder), "model", tc.model.String())
+		tc.path = main.blockOrder
+		tc.deadlock = true
+	case statusUnknown:
+		a.unreached.block(main.nextBlock, ReasonSolverTimeout)
+	}
+	return tc, status
+}
+
+// interleaved tells if path of testcase was already found by other interleaving of goroutines.
+func interleaved(state *State, testcases []Testcase, tc Testcase) bool {
+	if state.nextGoroutineId == 0 {
+		return false
+	}
+	return slices.ContainsFunc(testcases, func(other Testcase) bool {
+		return other.deadlock == tc.deadlock && other.panics == tc.panics && other.goPanic == tc.goPanic && slices.Equal(other.path, tc.path)
+	})
+}
+
+// makeChan makes channel with constant capacity.
+func (a *Analyzer) makeChan(state *State, v *ssa.MakeChan) {
+	size, ok := v.Size.(*ssa.Const)
+	if !ok {
+		panic(unsupported("channel", "with capacity '%s'", v.Size))
+	}
+	capacity, exact := constant.Int64Val(constant.ToInt(size.Value))
+	if !exact {
+		panic(unsupported("channel", "with capacity '%s'", v.Size))
+	}
+	state.channels = append(state.channels, &channel{
+		elem:     v.Type().Underlying().(*types.Chan).Elem(),
+		capacity: int(capacity),
+	})
+	frame := state.currentFrame()
+	frame.push(MakeChan{
+		Result: frame.newVar(v),
+		ID:     len(state.channels),
+	})
+}
+
+// snapshot is the value sent by send statement, sender can change value before it is received.
+func (s *State) snapshot(value Var) Var {
+	s.sent++
+	frame := s.currentFrame()
+	tmp := NewVar(&TempRegister{t: value.Type, name: fmt.Sprintf("send%d", s.sent)})
+	frame.push(Convert{
+		Result: tmp,
+		Arg:    value,
+	})
+	return tmp
+}
+
+// resolve forks state for every channel that each of chans can be and calls f with their numbers.
+func (a *Analyzer) resolve(state *State, fn *ssa.Function, chans []Var, ids []int, f func(*State, []int)) {
+	if len(chans) == 0 {
+		f(state, ids)
+		return
+	}
+	elem := chans[0].Type.Underlying().(*types.Chan).Elem()
+	for id := 0; id <= len(state.channels); id++ {
+		if id > 0 && !types.Identical(state.channels[id-1].elem, elem) {
+			continue
+		}
+		branchState := state.copy()
+		branchState.currentFrame().push(ChanIs{
+			Chan: chans[0],
+			ID:   id,
+		})
+		switch _, status := a.solve(fn, branchState.formula()); status {
+		case statusSat:
+			a.resolve(branchState, fn, chans[1:], append(slices.Clone(ids), id), f)
+		case statusUnknown:
+			a.unreached.block(state.main().nextBlock, ReasonSolverTimeout)
+		}
+	}
+}
+
+// communicate runs channel operation of running goroutine, state is queued for every case that can proceed.
+// Goroutine is blocked if no case can proceed, select with default case proceeds with it.
+func (a *Analyzer) communicate(queue Queue, state *State, op *chanOp) {
+	var ready []int
+	for k, c := range op.cases {
+		if state.ready(c) {
+			ready = append(ready, k)
+		}
+	}
+	if len(ready) == 0 {
+		if !op.blocking {
+			state.currentFrame().push(SelectCase{
+				Result: op.result,
+				Index:  -1,
+				Recv:   -1,
+			})
+			a.yield(queue, state)
+			return
+		}
+		state.suspend(false, op)
+		queue.push(state)
+		return
+	}
+	for _, k := range ready {
+		branchState := state.copy()
+		if branchState.proceed(op, k) {
+			a.yield(queue, branchState)
+		}
+	}
+}
+
+// ready tells if channel operation can proceed without waiting.
+func (s *State) ready(c chanCase) bool {
+	if c.ch == 0 {
+		return false
+	}
+	ch := s.channels[c.ch-1]
+	if c.send {
+		waiting, _ := s.waiting(c.ch, false)
+		return ch.closed || waiting != nil || len(ch.buffer) < ch.capacity
+	}
+	waiting, _ := s.waiting(c.ch, true)
+	return ch.closed || waiting != nil || len(ch.buffer) > 0
+}
+
+// proceed runs k-th case of channel operation of running goroutine.
+// It returns false if operation panics, i.e. send on closed channel.
+func (s *State) proceed(op *chanOp, k int) bool {
+	c := op.cases[k]
+	ch := s.channels[c.ch-1]
+	if c.send {
+		if ch.closed {
+			return false
+		}
+		if g, i := s.waiting(c.ch, false); g != nil {
+			s.wake(g, i, &c.value)
+		} else {
+			ch.buffer = append(ch.buffer, c.value)
+		}
+		s.complete(op, k, nil)
+		return true
+	}
+	var value *Var
+	if len(ch.buffer) > 0 {
+		value = &ch.buffer[0]
+		ch.buffer = ch.buffer[1:]
+		if g, i := s.waiting(c.ch, true); g != nil {
+			ch.buffer = append(ch.buffer, g.blocked.cases[i].value)
+			s.wake(g, i, nil)
+		}
+	} else if g, i := s.waiting(c.ch, true); g != nil {
+		value = &g.blocked.cases[i].value
+		s.wake(g, i, nil)
+	}
+	s.complete(op, k, value)
+	return true
+}
+
+// closeChan closes channel with number id, it returns message of the panic if channel is nil or closed.
+// It returns false if goroutines wait to send on it, they panic in Go, so such paths are infeasible.
+func (s *State) closeChan(id int) (string, bool) {
+	if id == 0 {
+		return "close of nil channel", false
+	}
+	if s.channels[id-1].closed {
+		return "close of closed channel", false
+	}
+	s.channels[id-1].closed = true
+	if g, _ := s.waiting(id, true); g != nil {
+		return "", false
+	}
+	for {
+		g, i := s.waiting(id, false)
+		if g == nil {
+			return "", true
+		}
+		s.wake(g, i, nil)
+	}
+}
+
+// waiting returns goroutine that waits to send to (or receive from) channel with number id and index of the case it waits with.
+// Goroutines that were blocked first are returned first.
+func (s *State) waiting(id int, send bool) (*goroutine, int) {
+	for _, g := range s.goroutines {
+		if g.blocked == nil {
+			continue
+		}
+		for i, c := range g.blocked.cases {
+			if c.ch == id && c.send == send {
+				return g, i
+			}
+		}
+	}
+	return nil, 0
+}
+
+// wake completes operation of blocked goroutine with its k-th case, value is received value.
+func (s *State) wake(g *goroutine, k int, value *Var) {
+	op := g.blocked
+	g.blocked = nil
+	s.complete(op, k, value)
+}
+
+// complete gives the result of channel operation that proceeded with k-th case.
+func (s *State) complete(op *chanOp, k int, value *Var) {
+	frame := s.currentFrame()
+	c := op.cases[k]
+	switch {
+	case op.isSelect:
+		recv := -1
+		if !c.send {
+			recv = c.recv
+		}
+		frame.push(SelectCase{
+			Result: op.result,
+			Index:  k,
+			Recv:   recv,
+			Value:  value,
+		})
+	case !c.send:
+		frame.push(Receive{
+			Result:  op.result,
+			Value:   value,
+			CommaOk: op.commaOk,
+		})
+	}
+}
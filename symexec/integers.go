@@ -0,0 +1,114 @@
+package symexec
+
+import (
+	"go/types"
+	"math"
+	"math/big"
+
+	"github.com/aclements/go-z3/z3"
+)
+
+// isInteger tells if values of type t are integers.
+func isInteger(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// isUnsigned tells if values of type t are unsigned integers.
+func isUnsigned(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsUnsigned != 0
+}
+
+// intWidth is the number of bits of integers of type t, int and uint are 64-bit.
+func intWidth(t types.Type) int {
+	switch t.Underlying().(*types.Basic).Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	default:
+		return intSize
+	}
+}
+
+// intSort is the sort of integers of type t.
+func (ctx *EncodingContext) intSort(t types.Type) z3.Sort {
+	if ctx.bitVectors {
+		return ctx.BVSort(intWidth(t))
+	}
+	return ctx.IntSort()
+}
+
+// intConst is integer constant i of type t, bit-vectors have the bits of i in two's complement.
+func (ctx *EncodingContext) intConst(i *big.Int, t types.Type) z3.Value {
+	if ctx.bitVectors {
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(intWidth(t)))
+		return ctx.FromBigInt(new(big.Int).Mod(i, mod), ctx.intSort(t))
+	}
+	return ctx.FromBigInt(i, ctx.IntSort())
+}
+
+// toInt is the value of integer v of type t as mathematical integer, it is used for indexes, lengths and positions.
+func (ctx *EncodingContext) toInt(v SymValue, t types.Type) z3.Int {
+	bv, ok := v.(z3.BV)
+	if !ok {
+		return v.(z3.Int)
+	}
+	if isUnsigned(t) {
+		return bv.UToInt()
+	}
+	return bv.SToInt()
+}
+
+// resize converts bit-vector v of integer type from to integer type to, it is truncated or extended by sign of from.
+func resize(v z3.BV, from types.Type, to types.Type) z3.BV {
+	have, want := intWidth(from), intWidth(to)
+	switch {
+	case have > want:
+		return v.Extract(want-1, 0)
+	case have < want && isUnsigned(from):
+		return v.ZeroExtend(want - have)
+	case have < want:
+		return v.SignExtend(want - have)
+	}
+	return v
+}
+
+// wrap converts mathematical integer v of integer type from to integer type to, values that don't fit wrap around like in Go.
+func (ctx *EncodingContext) wrap(v z3.Int, from types.Type, to types.Type) z3.Int {
+	have, want := intWidth(from), intWidth(to)
+	if isUnsigned(from) == isUnsigned(to) && have <= want || isUnsigned(from) && have < want {
+		return v
+	}
+	mod := ctx.FromBigInt(new(big.Int).Lsh(big.NewInt(1), uint(want)), ctx.IntSort()).(z3.Int)
+	if isUnsigned(to) {
+		return v.Mod(mod)
+	}
+	half := ctx.FromBigInt(new(big.Int).Lsh(big.NewInt(1), uint(want-1)), ctx.IntSort()).(z3.Int)
+	return v.Add(half).Mod(mod).Sub(half)
+}
+
+// fitsInt tells if float f without fraction fits in integer type t.
+// Go results of conversions that don't fit are implementation-specific, so such inputs are not considered.
+func (ctx *EncodingContext) fitsInt(f z3.Float, t types.Type) z3.Bool {
+	w := intWidth(t)
+	min, max := -math.Ldexp(1, w-1), math.Ldexp(1, w-1)
+	if isUnsigned(t) {
+		min, max = 0, math.Ldexp(1, w)
+	}
+	return f.GE(ctx.FromFloat64(min, f.Sort())).And(f.LT(ctx.FromFloat64(max, f.Sort())))
+}
+
+// shiftCount converts shift count of integer type countT to the width of shifted bit-vector of type t.
+// Counts that don't fit are replaced by the width, shifting by it gives the same result as in Go.
+func (ctx *EncodingContext) shiftCount(count z3.BV, countT types.Type, t types.Type) z3.BV {
+	have, want := intWidth(countT), intWidth(t)
+	if have <= want {
+		return count.ZeroExtend(want - have)
+	}
+	width := ctx.FromInt(int64(want), ctx.BVSort(have)).(z3.BV)
+	return count.UGE(width).IfThenElse(ctx.FromInt(int64(want), ctx.BVSort(want)), count.Extract(want-1, 0)).(z3.BV)
+}
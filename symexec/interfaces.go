@@ -0,0 +1,213 @@
+package symexec
+
+import (
+	"go/build"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aclements/go-z3/z3"
+	"golang.org/x/tools/go/ssa"
+)
+
+// isInterface tells if values of type t are encoded as Interface, errors are not.
+func isInterface(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Interface)
+	return ok && !isErrorType(t)
+}
+
+// typeID is the number of dynamic type t, numbers start from 1 because 0 is the type of nil interface.
+func (ctx *EncodingContext) typeID(t types.Type) z3.Int {
+	i := slices.IndexFunc(ctx.dynamicTypes, func(d types.Type) bool {
+		return types.Identical(d, t)
+	})
+	if i < 0 {
+		ctx.dynamicTypes = append(ctx.dynamicTypes, t)
+		i = len(ctx.dynamicTypes) - 1
+	}
+	return ctx.FromInt(int64(i+1), ctx.IntSort()).(z3.Int)
+}
+
+// dynamicType is the number of dynamic type of interface value with box at addr.
+// Nil has no dynamic type, its number is 0.
+func (ctx *EncodingContext) dynamicType(addr z3.Uninterpreted) z3.Int {
+	if ctx.typeOf == nil {
+		typeOf := ctx.FuncDecl("$type", []z3.Sort{ctx.addrSort}, ctx.IntSort())
+		ctx.typeOf = &typeOf
+		ctx.asserts = append(ctx.asserts, ctx.dynamicType(ctx.nilAddr).Eq(ctx.FromInt(0, ctx.IntSort()).(z3.Int)))
+	}
+	return ctx.typeOf.Apply(addr).(z3.Int)
+}
+
+// box returns memory of interface values with dynamic type t, boxes are pointers to values.
+func (ctx *EncodingContext) box(t types.Type) string {
+	ptr := types.NewPointer(t)
+	ctx.AddType(ptr)
+	return ptr.String()
+}
+
+// unbox is the value of dynamic type t in interface i.
+// Values in boxes of inputs are inputs too.
+func (ctx *EncodingContext) unbox(i *Interface, t types.Type) (z3.Value, z3.Bool) {
+	box := ctx.box(t)
+	value := ctx.valuesMemory[box].Select(i.addr)
+	if addr, ok := value.(z3.Uninterpreted); ok {
+		return value, ctx.isInput(i.addr).Implies(ctx.isInput(addr))
+	}
+	return value, ctx.FromBool(true)
+}
+
+// implements tells if dynamic type of non-nil interface value i implements iface.
+func (ctx *EncodingContext) implements(i *Interface, iface *types.Interface) z3.Bool {
+	res := ctx.FromBool(false)
+	for _, t := range slices.Clone(ctx.dynamicTypes) {
+		if types.Implements(t, iface) {
+			res = res.Or(ctx.dynamicType(i.addr).Eq(ctx.typeID(t)))
+		}
+	}
+	return res
+}
+
+// interfaceEq is true if interface values have identical dynamic types and equal values.
+func (ctx *EncodingContext) interfaceEq(left *Interface, right *Interface) z3.Bool {
+	leftT, rightT := ctx.dynamicType(left.addr), ctx.dynamicType(right.addr)
+	// the same box or both nil
+	res := left.addr.Eq(right.addr)
+	for _, t := range slices.Clone(ctx.dynamicTypes) {
+		if !types.Comparable(t) {
+			continue
+		}
+		id := ctx.typeID(t)
+		leftV, _ := ctx.unbox(left, t)
+		rightV, _ := ctx.unbox(right, t)
+		res = res.Or(leftT.Eq(id).And(rightT.Eq(id), ctx.valueEq(leftV, rightV, t)))
+	}
+	return res
+}
+
+// valueEq is Go equality of values of type t as stored in memory, structs are equal if their fields are.
+func (ctx *EncodingContext) valueEq(left z3.Value, right z3.Value, t types.Type) z3.Bool {
+	u, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return ctx.rawEq(left, right)
+	}
+	res := left.(z3.Uninterpreted).Eq(right.(z3.Uninterpreted))
+	eq := ctx.FromBool(true)
+	fields := ctx.fieldsMemory[t.String()]
+	for i := 0; i < u.NumFields(); i++ {
+		fieldT := u.Field(i).Type()
+		memory := ctx.valuesMemory[types.NewPointer(fieldT).String()]
+		leftF := memory.Select(fields[i].Select(left))
+		rightF := memory.Select(fields[i].Select(right))
+		eq = eq.And(ctx.valueEq(leftF, rightF, fieldT))
+	}
+	return res.Or(eq)
+}
+
+// constrainInputInterface makes input interface value i either nil or of one of dynamic types implementing its type.
+// Values of basic dynamic types are in range of their types.
+func (ctx *EncodingContext) constrainInputInterface(i *Interface, t types.Type) {
+	isNil := i.addr.Eq(ctx.nilAddr)
+	iface := t.Underlying().(*types.Interface)
+	ctx.asserts = append(ctx.asserts,
+		ctx.isInput(i.addr),
+		isNil.Or(ctx.implements(i, iface)),
+	)
+	for _, d := range slices.Clone(ctx.dynamicTypes) {
+		if !isBasic(d) || !types.Implements(d, iface) {
+			continue
+		}
+		value, _ := ctx.unbox(i, d)
+		ctx.asserts = append(ctx.asserts, ctx.dynamicType(i.addr).Eq(ctx.typeID(d)).Implies(ctx.inRange(value, d)))
+	}
+}
+
+// inputInterfaces evaluates dynamic types of interface parameters in model, nil interfaces have no type.
+// Values of basic dynamic types are evaluated too, values of other types are zero in tests.
+func (ctx *EncodingContext) inputInterfaces(params []*ssa.Parameter, model *z3.Model) (map[string]types.Type, map[string]string) {
+	ifaces := make(map[string]types.Type)
+	boxes := make(map[string]string)
+	for _, p := range params {
+		i, ok := ctx.vars[p.Name()].(*Interface)
+		if !ok {
+			continue
+		}
+		id, _, _ := model.Eval(ctx.dynamicType(i.addr), true).(z3.Int).AsInt64()
+		if id <= 0 {
+			ifaces[p.Name()] = nil
+			continue
+		}
+		t := ctx.dynamicTypes[id-1]
+		ifaces[p.Name()] = t
+		if isBasic(t) {
+			value, _ := ctx.unbox(i, t)
+			boxes[p.Name()] = modelValue(value, t, model)
+		}
+	}
+	return ifaces, boxes
+}
+
+// isStruct tells if underlying type of t is struct.
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// isBasic tells if underlying type of t is basic.
+func isBasic(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Basic)
+	return ok
+}
+
+// dynamicTypes are types that input interface values can hold.
+// These are named structs and named basic types declared outside of standard library and pointers to them, ordered by name.
+func dynamicTypes(prog *ssa.Program) []types.Type {
+	var res []types.Type
+	for _, pkg := range prog.AllPackages() {
+		if isStandard(prog, pkg) {
+			continue
+		}
+		for _, m := range pkg.Members {
+			tn, ok := m.(*ssa.Type)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			switch named.Underlying().(type) {
+			case *types.Struct, *types.Basic:
+				res = append(res, named, types.NewPointer(named))
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b types.Type) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return res
+}
+
+// isStandard tells if pkg is from GOROOT.
+func isStandard(prog *ssa.Program, pkg *ssa.Package) bool {
+	root := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	for _, m := range pkg.Members {
+		if pos := prog.Fset.Position(m.Pos()); pos.IsValid() {
+			return strings.HasPrefix(pos.Filename, root)
+		}
+	}
+	return false
+}
+
+// implementations returns types that invoke-mode call on interface of type t can be dispatched to.
+func implementations(dynamicTypes []types.Type, t types.Type) []types.Type {
+	var res []types.Type
+	iface := t.Underlying().(*types.Interface)
+	for _, d := range dynamicTypes {
+		if types.Implements(d, iface) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package symexec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// LogLevel is how much is logged, from nothing to every solver query.
+type LogLevel int
+
+const (
+	LogQuiet LogLevel = iota
+	// LogInfo logs analyzed functions, warnings and errors.
+	LogInfo
+	// LogDebug also logs SSA blocks and solver models for every path.
+	LogDebug
+	// LogTrace also logs every executed block and solver query.
+	LogTrace
+)
+
+// LevelTrace is slog level for LogTrace messages.
+const LevelTrace = slog.LevelDebug - 4
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogQuiet:
+		return "quiet"
+	case LogInfo:
+		return "info"
+	case LogDebug:
+		return "debug"
+	case LogTrace:
+		return "trace"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// ParseLogLevel is the inverse of LogLevel.String.
+func ParseLogLevel(s string) (LogLevel, error) {
+	for _, level := range []LogLevel{LogQuiet, LogInfo, LogDebug, LogTrace} {
+		if level.String() == s {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level '%s'", s)
+}
+
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case LogInfo:
+		return slog.LevelInfo
+	case LogDebug:
+		return slog.LevelDebug
+	case LogTrace:
+		return LevelTrace
+	default:
+		return slog.LevelError + 1
+	}
+}
+
+// NewLogger makes logger for Options.Logger.
+// Plain text is meant to be read by humans, multiline values (like SSA dumps) are printed as is.
+// Structured output is JSON, one object per line.
+func NewLogger(w io.Writer, level LogLevel, structured bool) *slog.Logger {
+	if level == LogQuiet {
+		return slog.New(discardHandler{})
+	}
+	if structured {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level: level.slogLevel(),
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.LevelKey && a.Value.Any() == LevelTrace {
+					a.Value = slog.StringValue("TRACE")
+				}
+				return a
+			},
+		}))
+	}
+	return slog.New(&plainHandler{w: w, level: level.slogLevel(), mu: &sync.Mutex{}})
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// plainHandler prints ':: message key=value ...', values with newlines are printed below.
+type plainHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+	mu    *sync.Mutex
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		sb.WriteString("[ERROR] ")
+	case r.Level >= slog.LevelWarn:
+		sb.WriteString("[WARNING] ")
+	default:
+		sb.WriteString(":: ")
+	}
+	sb.WriteString(r.Message)
+	var multiline []string
+	writeAttr := func(a slog.Attr) bool {
+		value := a.Value.Resolve().String()
+		if strings.Contains(value, "\n") {
+			multiline = append(multiline, strings.TrimRight(value, "\n"))
+		} else {
+			fmt.Fprintf(&sb, " %s=%s", a.Key, value)
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(writeAttr)
+	sb.WriteString("\n")
+	for _, m := range multiline {
+		sb.WriteString(m)
+		sb.WriteString("\n")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+func (h *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &plainHandler{w: h.w, level: h.level, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...), mu: h.mu}
+}
+
+// groups are not used by analyzer, attributes are printed without prefix
+func (h *plainHandler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -0,0 +1,39 @@
+package symexec
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Plain(t *testing.T) {
+	sb := &strings.Builder{}
+	logger := NewLogger(sb, LogInfo, false)
+	logger.Info("analyzing function", "function", "f")
+	logger.Debug("SSA blocks", "blocks", "0 ->\n  return\n")
+	logger.Warn("max depth reached", "path", "[0 1]")
+	logger.With("function", "g").Error("analysis failed", "stack", "a\nb")
+	want := ":: analyzing function function=f\n" +
+		"[WARNING] max depth reached path=[0 1]\n" +
+		"[ERROR] analysis failed function=g\na\nb\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLogger_Quiet(t *testing.T) {
+	sb := &strings.Builder{}
+	NewLogger(sb, LogQuiet, false).Error("analysis failed")
+	if sb.Len() != 0 {
+		t.Errorf("quiet logger printed '%s'", sb.String())
+	}
+}
+
+func TestLogger_Trace(t *testing.T) {
+	sb := &strings.Builder{}
+	logger := NewLogger(sb, LogTrace, true)
+	logger.Log(context.Background(), LevelTrace, "solver query", "sat", true)
+	if got := sb.String(); !strings.Contains(got, `"level":"TRACE","msg":"solver query","sat":true`) {
+		t.Errorf("got '%s'; want JSON trace message", got)
+	}
+}
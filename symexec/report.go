@@ -0,0 +1,148 @@
+package symexec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+type Report struct {
+	Packages []PackageReport `json:"packages"`
+}
+
+type PackageReport struct {
+	Input     string           `json:"input"`
+	Path      string           `json:"path"`
+	Functions []FunctionReport `json:"functions"`
+}
+
+type FunctionReport struct {
+	// Name is 'Func' or 'Type.Method'.
+	Name  string       `json:"name"`
+	File  string       `json:"file"`
+	Line  int          `json:"line"`
+	Paths []PathReport `json:"paths"`
+	// Error is set if analysis was aborted, Unsupported is also set if it was caused by unsupported feature.
+	Error       string             `json:"error,omitempty"`
+	Unsupported *UnsupportedReport `json:"unsupported,omitempty"`
+	Solver      SolverStats        `json:"solver"`
+	Coverage    *Coverage          `json:"coverage,omitempty"`
+}
+
+type UnsupportedReport struct {
+	Feature     string `json:"feature"`
+	Detail      string `json:"detail,omitempty"`
+	Instruction string `json:"instruction,omitempty"`
+	Position    string `json:"position,omitempty"`
+}
+
+type PathReport struct {
+	// Blocks are indexes of SSA blocks in execution order.
+	Blocks []int `json:"blocks"`
+	// Test is the name of generated test, 'Test_Func/1' for subtests, empty if test could not be generated.
+	Test string `json:"test,omitempty"`
+	// Inputs and Output are values from solver model, missing values can be anything.
+	// Output has a value for every result of function.
+	Inputs map[string]string `json:"inputs"`
+	Output []string          `json:"output,omitempty"`
+	// Panic is set if path ends with panic that is not recovered, there is no output.
+	Panic bool `json:"panic,omitempty"`
+	// Deadlock is set if path ends with all goroutines blocked, there is no output and no test.
+	Deadlock bool `json:"deadlock,omitempty"`
+	// GoPanic is set with Panic if the panic is in goroutine other than main, there is no test.
+	GoPanic bool `json:"goPanic,omitempty"`
+}
+
+// NewReport reports results of all packages, names of tests are the same as generated with style of package.
+func NewReport(res *Result, style func(pkg *PackageResult) TestStyle) *Report {
+	r := &Report{Packages: []PackageReport{}}
+	for _, pkg := range res.Packages {
+		pr := PackageReport{
+			Input:     pkg.Input,
+			Path:      pkg.Package.Pkg.Path(),
+			Functions: []FunctionReport{},
+		}
+		for _, fr := range pkg.Functions {
+			pr.Functions = append(pr.Functions, newFunctionReport(fr, style(pkg)))
+		}
+		r.Packages = append(r.Packages, pr)
+	}
+	return r
+}
+
+func newFunctionReport(fr *FunctionResult, style TestStyle) FunctionReport {
+	fn := fr.Function
+	pos := fn.Prog.Fset.Position(fn.Pos())
+	report := FunctionReport{
+		Name:     FunctionName(fn),
+		File:     pos.Filename,
+		Line:     pos.Line,
+		Paths:    []PathReport{},
+		Solver:   fr.Stats,
+		Coverage: fr.Coverage,
+	}
+	for i, tc := range fr.Testcases {
+		report.Paths = append(report.Paths, newPathReport(fn, i, tc, style))
+	}
+	if fr.Err != nil {
+		report.Error = fr.Err.Error()
+		var u *UnsupportedError
+		if errors.As(fr.Err, &u) {
+			report.Unsupported = &UnsupportedReport{Feature: u.Feature, Detail: u.Detail}
+			var analysisErr *AnalysisError
+			if errors.As(fr.Err, &analysisErr) {
+				if analysisErr.Instr != nil {
+					report.Unsupported.Instruction = analysisErr.Instr.String()
+				}
+				if analysisErr.Pos.IsValid() {
+					report.Unsupported.Position = analysisErr.Pos.String()
+				}
+			}
+		}
+	}
+	return report
+}
+
+func newPathReport(fn *ssa.Function, i int, tc Testcase, style TestStyle) PathReport {
+	vars := testcaseVars(tc)
+	var outputs []string
+	for i := 0; i < fn.Signature.Results().Len() && !tc.panics && !tc.deadlock; i++ {
+		outputs = append(outputs, vars[resultVar(fn.Signature, i)])
+	}
+	report := PathReport{
+		Blocks:   slices.Clone(tc.path),
+		Inputs:   make(map[string]string),
+		Output:   outputs,
+		Panic:    tc.panics,
+		Deadlock: tc.deadlock,
+		GoPanic:  tc.goPanic,
+	}
+	if _, err := renderTestcase(fn, i, tc); err == nil && !tc.deadlock && !tc.goPanic {
+		report.Test = testName(fn, i)
+		if style == StyleSubtests {
+			report.Test = subtestName(fn, i)
+		}
+	}
+	for _, param := range fn.Params {
+		if m, ok := tc.maps[param.Name()]; ok {
+			report.Inputs[param.Name()] = m.String()
+		} else if t, ok := tc.ifaces[param.Name()]; ok {
+			report.Inputs[param.Name()] = "nil"
+			if t != nil {
+				report.Inputs[param.Name()] = t.String()
+			}
+		} else if value, ok := vars[param.Name()]; ok {
+			report.Inputs[param.Name()] = value
+		}
+	}
+	return report
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
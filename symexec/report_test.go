@@ -0,0 +1,65 @@
+package symexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func decodedReport(t *testing.T, style TestStyle) FunctionReport {
+	opts := Options{
+		Files:   []string{"invokes/simpleCalls.go"},
+		Include: []*regexp.Regexp{regexp.MustCompile(`^InvokeExample\.SimpleFormula$`)},
+	}
+	res, err := NewAnalyzer(opts).Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := NewReport(res, func(*PackageResult) TestStyle {
+		return style
+	})
+	var b bytes.Buffer
+	if err := report.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Packages) != 1 || len(decoded.Packages[0].Functions) != 1 {
+		t.Fatalf("got %+v; want single package with single function", decoded)
+	}
+	return decoded.Packages[0].Functions[0]
+}
+
+func TestReport_Functions(t *testing.T) {
+	fr := decodedReport(t, StyleFunctions)
+	if fr.Name != "InvokeExample.SimpleFormula" || fr.Line != 30 {
+		t.Errorf("got function '%s' at line %d; want 'InvokeExample.SimpleFormula' at line 30", fr.Name, fr.Line)
+	}
+	if len(fr.Paths) != 3 {
+		t.Fatalf("got %d paths; want 3", len(fr.Paths))
+	}
+	for i, path := range fr.Paths {
+		if want := fmt.Sprintf("Test_InvokeExample_SimpleFormula_%d", i+1); path.Test != want {
+			t.Errorf("path %d: got test '%s'; want '%s'", i, path.Test, want)
+		}
+		if len(path.Output) != 2 {
+			t.Errorf("path %d: got output %q; want value for every result", i, path.Output)
+		}
+		if _, ok := path.Inputs["fst"]; !ok {
+			t.Errorf("path %d: got inputs %v; want 'fst'", i, path.Inputs)
+		}
+	}
+}
+
+func TestReport_Subtests(t *testing.T) {
+	fr := decodedReport(t, StyleSubtests)
+	for i, path := range fr.Paths {
+		if want := fmt.Sprintf("Test_InvokeExample_SimpleFormula/%d", i+1); path.Test != want {
+			t.Errorf("path %d: got test '%s'; want '%s'", i, path.Test, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package symexec
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+
+	"github.com/aclements/go-z3/z3"
+)
+
+// maxCopyLen is the upper bound of the number of elements copied by copy and append.
+// Copies are encoded for every index below the bound because there are no quantifiers.
+const maxCopyLen = 8
+
+// sizeClasses are sizes of small objects allocated by Go runtime, grown backing arrays fill their size class.
+var sizeClasses = []int64{
+	8, 16, 24, 32, 48, 64, 80, 96, 112, 128, 144, 160, 176, 192, 208, 224, 240, 256, 288, 320, 352, 384, 416, 448, 480, 512,
+	576, 640, 704, 768, 896, 1024, 1152, 1280, 1408, 1536, 1792, 2048, 2304, 2688, 3072, 3200, 3456, 4096, 4864, 5376, 6144,
+	6528, 6784, 6912, 8192, 9472, 9728, 10240, 10880, 12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264,
+	28672, 32768,
+}
+
+const (
+	maxSmallSize           = 32768
+	mallocHeaderSize       = 8
+	minSizeForMallocHeader = 512
+	pageSize               = 8192
+)
+
+// sizes are sizes of types on the platform of generated tests.
+var sizes = types.SizesFor("gc", "amd64")
+
+// sliceHeader is the window of slice into its backing array, slices of the same backing array share elements.
+type sliceHeader struct {
+	backing z3.Uninterpreted
+	offset  z3.Int
+	len     z3.Int
+	cap     z3.Int
+}
+
+func (ctx *EncodingContext) initialSliceMemory(t *types.Slice) (backing z3.Array, offsets z3.Array, lens z3.Array, caps z3.Array) {
+	memory := func(name string, sort z3.Sort) z3.Array {
+		return ctx.Const(fmt.Sprintf("$<%s>%sMemory", t, name), ctx.ArraySort(ctx.addrSort, sort)).(z3.Array)
+	}
+	return memory("Backing", ctx.addrSort), memory("Offset", ctx.IntSort()), memory("Len", ctx.IntSort()), memory("Cap", ctx.IntSort())
+}
+
+// header reads header of slice s.
+// Input slices start at the beginning of input backing arrays, their length does not exceed capacity.
+func (ctx *EncodingContext) header(s *SymArray) sliceHeader {
+	h := sliceHeader{
+		backing: ctx.arrayBackingMemory[s.t].Select(s.addr).(z3.Uninterpreted),
+		offset:  ctx.arrayOffsetMemory[s.t].Select(s.addr).(z3.Int),
+		len:     ctx.arrayLenMemory[s.t].Select(s.addr).(z3.Int),
+		cap:     ctx.arrayCapMemory[s.t].Select(s.addr).(z3.Int),
+	}
+	zero := ctx.FromInt(0, ctx.IntSort()).(z3.Int)
+	ctx.asserts = append(ctx.asserts, ctx.isInput(s.addr).Implies(
+		h.offset.Eq(zero).And(zero.LE(h.len), h.len.LE(h.cap), ctx.isInput(h.backing)),
+	))
+	return h
+}
+
+// setHeader allocates slice s with header h.
+func (ctx *EncodingContext) setHeader(s *SymArray, h sliceHeader) z3.Bool {
+	ctx.arrayBackingMemory[s.t] = ctx.arrayBackingMemory[s.t].Store(s.addr, h.backing)
+	ctx.arrayOffsetMemory[s.t] = ctx.arrayOffsetMemory[s.t].Store(s.addr, h.offset)
+	ctx.arrayLenMemory[s.t] = ctx.arrayLenMemory[s.t].Store(s.addr, h.len)
+	ctx.arrayCapMemory[s.t] = ctx.arrayCapMemory[s.t].Store(s.addr, h.cap)
+	return ctx.allocate(s.addr)
+}
+
+// sliceValue evaluates length and elements of slice of type t at addr in model, nil slice has no length.
+// Length of slice 's' is 'len(s)', element i is 's[i]' like elements of arrays.
+// Input slices are read from memory before any stores, other slices after all stores.
+func (ctx *EncodingContext) sliceValue(name string, t *types.Slice, addr z3.Uninterpreted, input bool, model *z3.Model, values map[string]string) {
+	if isNil, _ := model.Eval(addr.Eq(ctx.nilAddr), true).(z3.Bool).AsBool(); isNil {
+		return
+	}
+	ptrT := types.NewPointer(t.Elem()).String()
+	backing, offsets, lens := ctx.arrayBackingMemory[t.String()], ctx.arrayOffsetMemory[t.String()], ctx.arrayLenMemory[t.String()]
+	memory := ctx.valuesMemory[ptrT]
+	if input {
+		backing, offsets, lens, _ = ctx.initialSliceMemory(t)
+		memory = ctx.Const(fmt.Sprintf("$<%s>Memory", ptrT), memory.Sort()).(z3.Array)
+	}
+	n, _, _ := model.Eval(lens.Select(addr), true).(z3.Int).AsInt64()
+	values[fmt.Sprintf("len(%s)", name)] = strconv.FormatInt(n, 10)
+	b, offset := backing.Select(addr), offsets.Select(addr).(z3.Int)
+	for i := int64(0); i < n; i++ {
+		k := offset.Add(ctx.FromInt(i, ctx.IntSort()).(z3.Int))
+		value := memory.Select(ctx.elemOf.Apply(b, k))
+		elemName := fmt.Sprintf("%s[%d]", name, i)
+		switch inner := t.Elem().Underlying().(type) {
+		case *types.Slice:
+			ctx.sliceValue(elemName, inner, value.(z3.Uninterpreted), input, model, values)
+		case *types.Array:
+			ctx.arrayValue(elemName, inner, value.(z3.Uninterpreted), input, model, values)
+		default:
+			values[elemName] = modelValue(value, t.Elem(), model)
+		}
+	}
+}
+
+// newBacking is backing array allocated by analyzed code.
+func (ctx *EncodingContext) newBacking() (z3.Uninterpreted, z3.Bool) {
+	backing := ctx.Const(fmt.Sprintf("$alloc%d", ctx.allocs+1), ctx.addrSort).(z3.Uninterpreted)
+	return backing, ctx.allocate(backing)
+}
+
+// elemAddr is the address of element i of backing array, elements of type elemT are stored in memory of pointer type ptrT.
+// Elements have allocation number of their backing array and differ by index, so they are distinct from other objects.
+// Elements of backing arrays allocated by analyzed code are zero until they are stored.
+// Elements of input backing arrays fit in their type, nested arrays are input too.
+func (ctx *EncodingContext) elemAddr(backing z3.Uninterpreted, i z3.Int, ptrT string, elemT types.Type) (z3.Uninterpreted, z3.Bool) {
+	elem := ctx.elemOf.Apply(backing, i).(z3.Uninterpreted)
+	res := ctx.heapOf(elem).Eq(ctx.heapOf(backing)).And(
+		ctx.indexOf.Apply(elem).(z3.Int).Eq(i),
+		ctx.indexOf.Apply(backing).(z3.Int).Eq(ctx.FromInt(-1, ctx.IntSort()).(z3.Int)),
+	)
+	initial := ctx.Const(fmt.Sprintf("$<%s>Memory", ptrT), ctx.valuesMemory[ptrT].Sort()).(z3.Array)
+	if zero := ctx.zeroValue(elemT); zero != nil {
+		res = res.And(ctx.isInput(backing).Not().Implies(ctx.rawEq(initial.Select(elem), zero)))
+	}
+	if isArray(elemT) {
+		res = res.And(ctx.isInput(backing).Implies(ctx.isInput(initial.Select(elem).(z3.Uninterpreted))))
+	}
+	res = res.And(ctx.isInput(backing).Implies(ctx.inRange(initial.Select(elem), elemT)))
+	return elem, res
+}
+
+// copyElems copies n elements from backing array src at srcOffset to backing array dst at dstOffset.
+// All elements are read before they are written, so overlapping elements are copied like in Go.
+// Copies of more than maxCopyLen elements are infeasible.
+func (ctx *EncodingContext) copyElems(elemT types.Type, dst z3.Uninterpreted, dstOffset z3.Int, src z3.Uninterpreted, srcOffset z3.Int, n z3.Int) z3.Bool {
+	ptrT := types.NewPointer(elemT).String()
+	res := n.LE(ctx.FromInt(maxCopyLen, ctx.IntSort()).(z3.Int))
+	var values []z3.Value
+	for k := 0; k < maxCopyLen; k++ {
+		i := ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int)
+		elem, valid := ctx.elemAddr(src, srcOffset.Add(i), ptrT, elemT)
+		res = res.And(valid)
+		values = append(values, ctx.valuesMemory[ptrT].Select(elem))
+	}
+	for k, value := range values {
+		i := ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int)
+		elem, valid := ctx.elemAddr(dst, dstOffset.Add(i), ptrT, elemT)
+		res = res.And(valid)
+		old := ctx.valuesMemory[ptrT].Select(elem)
+		ctx.valuesMemory[ptrT] = ctx.valuesMemory[ptrT].Store(elem, i.LT(n).IfThenElse(value, old))
+	}
+	return res
+}
+
+// min is the smaller of a and b.
+func (ctx *EncodingContext) min(a z3.Int, b z3.Int) z3.Int {
+	return a.LE(b).IfThenElse(a, b).(z3.Int)
+}
+
+// growCap is the capacity of backing array allocated by append for length l when it exceeds capacity c, like in Go runtime.
+// Capacity is rounded up to size classes of Go 1.22 and later on 64-bit platforms, see sizeClasses.
+func (ctx *EncodingContext) growCap(l z3.Int, c z3.Int, elemT types.Type) z3.Int {
+	num := func(v int64) z3.Int {
+		return ctx.FromInt(v, ctx.IntSort()).(z3.Int)
+	}
+	size := sizes.Sizeof(elemT)
+	if size == 0 {
+		return l
+	}
+	// large slices grow by a quarter once, appended elements don't exceed maxCopyLen
+	double := c.Add(c)
+	newCap := l.GT(double).IfThenElse(l,
+		c.LT(num(256)).IfThenElse(double, c.Add(c.Add(num(768)).Div(num(4))))).(z3.Int)
+	mem := newCap.Mul(num(size))
+	header := num(0)
+	if containsPointers(elemT) {
+		header = mem.GT(num(minSizeForMallocHeader)).IfThenElse(num(mallocHeaderSize), num(0)).(z3.Int)
+	}
+	req := mem.Add(header)
+	// objects that don't fit in size classes take whole pages
+	rounded := req.Add(num(pageSize - 1)).Div(num(pageSize)).Mul(num(pageSize))
+	for i := len(sizeClasses) - 1; i >= 0; i-- {
+		rounded = req.LE(num(sizeClasses[i])).IfThenElse(num(sizeClasses[i]), rounded).(z3.Int)
+	}
+	small := mem.LE(num(maxSmallSize - mallocHeaderSize))
+	capMem := small.IfThenElse(rounded.Sub(header), mem.Add(num(pageSize-1)).Div(num(pageSize)).Mul(num(pageSize))).(z3.Int)
+	return capMem.Div(num(size))
+}
+
+// containsPointers tells if values of type t contain pointers, Go runtime allocates them with header if they are large.
+func containsPointers(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.String || u.Kind() == types.UnsafePointer
+	case *types.Array:
+		return u.Len() > 0 && containsPointers(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsPointers(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
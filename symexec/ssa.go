@@ -1,35 +1,78 @@
 package symexec
 
 import (
+	"errors"
 	"fmt"
-	"go/ast"
-	"go/importer"
-	"go/parser"
-	"go/token"
+	"go/constant"
 	"go/types"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 const (
-	realFunc  = "real"
-	imagFunc  = "imag"
-	lenFunc   = "len"
-	mathInf   = "math.Inf"
-	mathIsNaN = "math.IsNaN"
+	realFunc   = "real"
+	imagFunc   = "imag"
+	lenFunc    = "len"
+	capFunc    = "cap"
+	copyFunc   = "copy"
+	appendFunc = "append"
+	deleteFunc = "delete"
+	mathInf    = "math.Inf"
+	mathIsNaN  = "math.IsNaN"
+	mathAbs    = "math.Abs"
+	errorsNew  = "errors.New"
+	fmtErrorf  = "fmt.Errorf"
 )
 
 func IsBuiltIn(name string) bool {
 	switch name {
-	case realFunc, imagFunc, lenFunc, mathInf, mathIsNaN:
+	case realFunc, imagFunc, lenFunc, capFunc, copyFunc, appendFunc, deleteFunc, mathInf, mathIsNaN, mathAbs, errorsNew, fmtErrorf:
 		return true
 	default:
 		return false
 	}
 }
 
+// isRecover tells if call is built-in 'recover', it is not a regular built-in because it depends on panicking state.
+func isRecover(call *ssa.CallCommon) bool {
+	b, ok := call.Value.(*ssa.Builtin)
+	return ok && b.Name() == "recover"
+}
+
+// isUnused tells if t is the type of key or value of range statement that is not used, it is invalid type in ssa.
+func isUnused(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Kind() == types.Invalid
+}
+
+// isClose tells if call is built-in 'close', it is not a regular built-in because it changes channel state.
+func isClose(call *ssa.CallCommon) bool {
+	b, ok := call.Value.(*ssa.Builtin)
+	return ok && b.Name() == "close"
+}
+
+const (
+	symbolicPackage = "slava0135/gobber/symbolic"
+	symbolicMake    = "MakeSymbolic"
+	symbolicAssume  = "Assume"
+)
+
+// isSymbolic tells if fn is function from 'symbolic' package, that is used in mocks.
+func isSymbolic(fn *ssa.Function, name string) bool {
+	if fn == nil {
+		return false
+	}
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	return fn.Pkg != nil && fn.Pkg.Pkg.Path() == symbolicPackage && fn.Name() == name
+}
+
 type Register interface {
 	Type() types.Type
 	Name() string
@@ -40,6 +83,21 @@ type TempRegister struct {
 	name string
 }
 
+// registerName is the name of register, constants have full value unlike ssa.Const.Name.
+// Functions used as values are constants too.
+func registerName(reg Register) string {
+	switch reg := reg.(type) {
+	case *ssa.Const:
+		if reg.Value != nil && reg.Value.Kind() == constant.String {
+			// long string constants are abbreviated
+			return strconv.Quote(constant.StringVal(reg.Value)) + ":" + reg.Type().String()
+		}
+	case *ssa.Function:
+		return reg.String() + ":" + reg.Type().String()
+	}
+	return reg.Name()
+}
+
 func (t *TempRegister) Type() types.Type {
 	return t.t
 }
@@ -48,35 +106,59 @@ func (t *TempRegister) Name() string {
 	return t.name
 }
 
-func buildPackage(filename string) *ssa.Package {
-	fmt.Printf(":: building SSA graph for file '%s'\n", filename)
-
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filename, nil, 0)
-	if err != nil {
-		panic(err)
+// loadPackages type-checks packages matching patterns (relative to dir) together with their dependencies and builds SSA.
+// Patterns are the same as for 'go build', a list of '.go' files makes a single package.
+// Returned SSA packages correspond to patterns, dependencies can be found through Prog.
+// Function bodies are built only for these packages and other packages of the main module,
+// functions of other dependencies (standard library included) are external.
+func loadPackages(dir string, patterns ...string) ([]*ssa.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Dir: dir,
 	}
-
-	files := []*ast.File{f}
-
-	pkg := types.NewPackage("main", "")
-
-	main, _, err := ssautil.BuildPackage(&types.Config{Importer: importer.Default()}, fset, pkg, files, 0)
+	initial, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("no packages matching %s", strings.Join(patterns, " "))
+	}
+	var errs []error
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			errs = append(errs, e)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	prog := ssa.NewProgram(initial[0].Fset, ssa.InstantiateGenerics)
+	created := make(map[*packages.Package]*ssa.Package)
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		if slices.Contains(initial, p) || (p.Module != nil && p.Module.Main) {
+			created[p] = prog.CreatePackage(p.Types, p.Syntax, p.TypesInfo, true)
+		} else {
+			created[p] = prog.CreatePackage(p.Types, nil, nil, true)
+		}
+	})
+	prog.Build()
+	var pkgs []*ssa.Package
+	for _, p := range initial {
+		pkgs = append(pkgs, created[p])
 	}
-	return main
+	return pkgs, nil
 }
 
-func printBlocks(fn *ssa.Function) {
+func printBlocks(w io.Writer, fn *ssa.Function) {
 	for _, v := range fn.Blocks {
-		fmt.Println(v.String(), "->")
+		fmt.Fprintln(w, v.String(), "->")
 		for _, v := range v.Instrs {
 			printInstr := func(name string) {
 				if reg, ok := v.(Register); ok {
-					fmt.Printf("  [%10s] %s:%s <-- %s\n", strings.ToUpper(name), reg.Name(), reg.Type(), v.String())
+					fmt.Fprintf(w, "  [%10s] %s:%s <-- %s\n", strings.ToUpper(name), reg.Name(), reg.Type(), v.String())
 				} else {
-					fmt.Printf("  [%10s] %s\n", strings.ToUpper(name), v.String())
+					fmt.Fprintf(w, "  [%10s] %s\n", strings.ToUpper(name), v.String())
 				}
 			}
 			switch v.(type) {
@@ -121,7 +203,7 @@ func printBlocks(fn *ssa.Function) {
 			case *ssa.UnOp:
 				printInstr("unop")
 			default:
-				panic(fmt.Sprintf("unknown instruction '%s'", v.String()))
+				printInstr("?")
 			}
 		}
 	}
@@ -3,6 +3,7 @@ package symexec
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
 
@@ -11,9 +12,9 @@ import (
 )
 
 func Static() {
-	os.Chdir("testdata")
+	const dir = "testdata"
 
-	testcases, err := os.ReadDir("./")
+	testcases, err := os.ReadDir(dir)
 	if err != nil {
 		panic(err)
 	}
@@ -22,12 +23,17 @@ func Static() {
 		if tc.IsDir() || strings.HasSuffix(tc.Name(), "_test.go") {
 			continue
 		}
-		AnalyzeFileStatic(tc.Name())
+		AnalyzeFileStatic(filepath.Join(dir, tc.Name()))
 	}
 }
 
 func AnalyzeFileStatic(filename string) map[string]bool {
-	main := buildPackage(filename)
+	fmt.Printf(":: building SSA graph for file '%s'\n", filename)
+	pkgs, err := loadPackages("", filename)
+	if err != nil {
+		panic(err)
+	}
+	main := pkgs[0]
 	res := make(map[string]bool, 0)
 	for _, v := range main.Members {
 		if fn, ok := v.(*ssa.Function); ok && fn.Name() != "init" {
@@ -47,7 +53,7 @@ func staticFunction(fn *ssa.Function) (ok bool) {
 	}()
 	fmt.Println("::", "analyzing function", "'"+fn.Name()+"'")
 	fmt.Println("::", "printing SSA blocks")
-	printBlocks(fn)
+	printBlocks(os.Stdout, fn)
 	fmt.Println("::", "building formula")
 	f := makeFormula(fn)
 	fmt.Println("::", "encoding formula")
@@ -156,10 +162,12 @@ func encodeFormula(fn *ssa.Function, f Formula) {
 	fmt.Println("::", "listing all variables")
 	vars := make(map[string]Var, 0)
 	f.ScanVars(vars)
-	vars[resultSpecialVar] = Var{
-		Name:     resultSpecialVar,
-		Type:     fn.Signature.Results().At(0).Type(),
-		Constant: false,
+	if t := resultType(fn.Signature); t != nil {
+		vars[resultSpecialVar] = Var{
+			Name:     resultSpecialVar,
+			Type:     t,
+			Constant: false,
+		}
 	}
 	for _, v := range vars {
 		fmt.Print(v, " ")
@@ -173,17 +181,25 @@ func encodeFormula(fn *ssa.Function, f Formula) {
 		vars:     make(map[string]SymValue, 0),
 		rawTypes: make(map[string]z3.Sort, 0),
 
-		fieldsMemory:      make(map[string][]z3.Array),
-		valuesMemory:      make(map[string]z3.Array),
-		arrayValuesMemory: make(map[string]z3.Array),
-		arrayLenMemory:    make(map[string]z3.Array),
+		fieldsMemory:       make(map[string][]z3.Array),
+		valuesMemory:       make(map[string]z3.Array),
+		arrayBackingMemory: make(map[string]z3.Array),
+		arrayOffsetMemory:  make(map[string]z3.Array),
+		arrayLenMemory:     make(map[string]z3.Array),
+		arrayCapMemory:     make(map[string]z3.Array),
+		mapValuesMemory:    make(map[string]z3.Array),
+		mapPresentMemory:   make(map[string]z3.Array),
+		mapLenMemory:       make(map[string]z3.Array),
+		mapKeys:            make(map[string][]z3.Value),
 
 		floatSort:   z3ctx.FloatSort(11, 53),
+		float32Sort: z3ctx.FloatSort(8, 24),
 		complexSort: z3ctx.UninterpretedSort("complex128"),
 		stringSort:  z3ctx.UninterpretedSort("string"),
 
 		addrSort: z3ctx.UninterpretedSort("$addr"),
 	}
+	ctx.initHeap()
 
 	for _, v := range vars {
 		ctx.AddType(v.Type)
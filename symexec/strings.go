@@ -0,0 +1,218 @@
+package symexec
+
+import (
+	"go/types"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/aclements/go-z3/z3"
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxStringLen is the upper bound of length of input strings and strings stored in memory.
+// Z3 string theory is not available in go-z3, so strings are bounded sequences of bytes,
+// comparisons are encoded for every index below the bound because there are no quantifiers.
+// Paths that need longer input strings are never found, functions that store strings
+// that may be longer (e.g. concatenation of inputs) are not supported.
+const maxStringLen = 32
+
+const byteSize = 8
+
+// stringLenBits is the width of length of strings in memory, see rawString.
+const stringLenBits = 16
+
+const stringBits = stringLenBits + byteSize*maxStringLen
+
+// StringConst is string variable, its bytes are stored in array.
+func (ctx *EncodingContext) StringConst(name string) *String {
+	bytes := ctx.Const(name+".BYTES", ctx.ArraySort(ctx.IntSort(), ctx.BVSort(byteSize))).(z3.Array)
+	return &String{
+		len: ctx.IntConst(name + ".LEN"),
+		at: func(i z3.Int) z3.BV {
+			return bytes.Select(i).(z3.BV)
+		},
+		max:  maxStringLen,
+		sort: ctx.stringSort,
+	}
+}
+
+// FromString is string constant.
+func (ctx *EncodingContext) FromString(s string) *String {
+	return &String{
+		len: ctx.FromInt(int64(len(s)), ctx.IntSort()).(z3.Int),
+		at: func(i z3.Int) z3.BV {
+			b := ctx.FromInt(0, ctx.BVSort(byteSize)).(z3.BV)
+			for k := len(s) - 1; k >= 0; k-- {
+				isK := i.Eq(ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int))
+				b = isK.IfThenElse(ctx.FromInt(int64(s[k]), ctx.BVSort(byteSize)), b).(z3.BV)
+			}
+			return b
+		},
+		max:  len(s),
+		sort: ctx.stringSort,
+	}
+}
+
+func (ctx *EncodingContext) concat(left *String, right *String) *String {
+	return &String{
+		len: left.len.Add(right.len),
+		at: func(i z3.Int) z3.BV {
+			return i.LT(left.len).IfThenElse(left.at(i), right.at(i.Sub(left.len))).(z3.BV)
+		},
+		max:  left.max + right.max,
+		sort: ctx.stringSort,
+	}
+}
+
+// substring is s[low:high], bounds must be checked separately.
+func (ctx *EncodingContext) substring(s *String, low z3.Int, high z3.Int) *String {
+	return &String{
+		len: high.Sub(low),
+		at: func(i z3.Int) z3.BV {
+			return s.at(i.Add(low))
+		},
+		max:  s.max,
+		sort: ctx.stringSort,
+	}
+}
+
+// stringEq compares first n bytes, n must not be less than length of strings.
+func (ctx *EncodingContext) stringEq(left *String, right *String, n int) z3.Bool {
+	res := left.len.Eq(right.len)
+	for k := 0; k < n; k++ {
+		i := ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int)
+		res = res.And(i.LT(left.len).Implies(left.at(i).Eq(right.at(i))))
+	}
+	return res
+}
+
+// stringLess compares strings lexicographically by bytes.
+func (ctx *EncodingContext) stringLess(left *String, right *String) z3.Bool {
+	res := ctx.FromBool(false)
+	// samePrefix is true if first k bytes are equal
+	samePrefix := ctx.FromBool(true)
+	for k := 0; k <= min(left.max, right.max); k++ {
+		i := ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int)
+		inLeft, inRight := i.LT(left.len), i.LT(right.len)
+		// left is a prefix of right or the first different byte is less
+		res = res.Or(samePrefix.And(inRight, inLeft.Not().Or(left.at(i).ULT(right.at(i)))))
+		samePrefix = samePrefix.And(inLeft, inRight, left.at(i).Eq(right.at(i)))
+	}
+	return res
+}
+
+// decodeRune decodes UTF-8 rune at byte i like range statement does, it also returns the number of bytes of rune.
+// Invalid encodings give utf8.RuneError of a single byte.
+func (ctx *EncodingContext) decodeRune(s *String, i z3.Int) (z3.Int, z3.Int) {
+	bv := func(v int64, size int) z3.BV {
+		return ctx.FromInt(v, ctx.BVSort(size)).(z3.BV)
+	}
+	var b [4]z3.BV
+	// cont[k] is true if k-th byte is in string and is a continuation byte
+	var cont [4]z3.Bool
+	for k := range b {
+		at := i.Add(ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int))
+		b[k] = s.at(at)
+		cont[k] = at.LT(s.len).And(b[k].Extract(7, 6).Eq(bv(0b10, 2)))
+	}
+	r2 := b[0].Extract(4, 0).Concat(b[1].Extract(5, 0)).ZeroExtend(21)
+	r3 := b[0].Extract(3, 0).Concat(b[1].Extract(5, 0)).Concat(b[2].Extract(5, 0)).ZeroExtend(16)
+	r4 := b[0].Extract(2, 0).Concat(b[1].Extract(5, 0)).Concat(b[2].Extract(5, 0)).Concat(b[3].Extract(5, 0)).ZeroExtend(11)
+	// overlong encodings, surrogates and runes above unicode.MaxRune are invalid
+	one := b[0].Extract(7, 7).Eq(bv(0, 1))
+	two := b[0].Extract(7, 5).Eq(bv(0b110, 3)).And(cont[1], r2.UGE(bv(0x80, 32)))
+	three := b[0].Extract(7, 4).Eq(bv(0b1110, 4)).And(cont[1], cont[2], r3.UGE(bv(0x800, 32)),
+		r3.ULT(bv(0xD800, 32)).Or(r3.UGT(bv(0xDFFF, 32))))
+	four := b[0].Extract(7, 3).Eq(bv(0b11110, 5)).And(cont[1], cont[2], cont[3], r4.UGE(bv(0x10000, 32)), r4.ULE(bv(0x10FFFF, 32)))
+	r := one.IfThenElse(b[0].ZeroExtend(24),
+		two.IfThenElse(r2,
+			three.IfThenElse(r3,
+				four.IfThenElse(r4, bv(utf8.RuneError, 32))))).(z3.BV)
+	width := func(n int64) z3.Int {
+		return ctx.FromInt(n, ctx.IntSort()).(z3.Int)
+	}
+	w := two.IfThenElse(width(2), three.IfThenElse(width(3), four.IfThenElse(width(4), width(1)))).(z3.Int)
+	return r.UToInt(), w
+}
+
+// rawString is bit-vector of string s stored in memory, its length is in the lowest bits and bytes follow it.
+// Bytes after the end are zero, so equal strings have equal bit-vectors and can be map keys.
+// Strings that may be longer than maxStringLen are not supported, other strings that are longer are not equal to any string in memory.
+func (ctx *EncodingContext) rawString(s *String) z3.BV {
+	if s.max > maxStringLen {
+		panic(unsupported("string in memory", "longer than %d bytes", maxStringLen))
+	}
+	max := ctx.FromInt(maxStringLen, ctx.IntSort()).(z3.Int)
+	n := s.len.LE(max).IfThenElse(s.len, max.Add(ctx.FromInt(1, ctx.IntSort()).(z3.Int))).(z3.Int)
+	raw := n.ToBV(stringLenBits)
+	zero := ctx.FromInt(0, ctx.BVSort(byteSize)).(z3.BV)
+	for k := 0; k < maxStringLen; k++ {
+		i := ctx.FromInt(int64(k), ctx.IntSort()).(z3.Int)
+		raw = i.LT(s.len).IfThenElse(s.at(i), zero).(z3.BV).Concat(raw)
+	}
+	return raw
+}
+
+// isRawString tells if raw is a string in memory, input memory may have other bit-vectors.
+func (ctx *EncodingContext) isRawString(raw z3.BV) z3.Bool {
+	return raw.Extract(stringLenBits-1, 0).ULE(ctx.FromInt(maxStringLen, ctx.BVSort(stringLenBits)).(z3.BV))
+}
+
+// rawStringValue is the string in memory with value of bit-vector raw.
+func rawStringValue(raw *big.Int) string {
+	mask := big.NewInt(1<<byteSize - 1)
+	n := new(big.Int).And(raw, big.NewInt(1<<stringLenBits-1)).Int64()
+	bytes := make([]byte, 0, n)
+	for k := int64(0); k < n && k < maxStringLen; k++ {
+		b := new(big.Int).Rsh(raw, uint(stringLenBits+byteSize*k))
+		bytes = append(bytes, byte(b.And(b, mask).Int64()))
+	}
+	return string(bytes)
+}
+
+// modelValue evaluates value in memory v of type t in model, strings are quoted.
+func modelValue(v z3.Value, t types.Type, model *z3.Model) string {
+	if u, ok := t.Underlying().(*types.Basic); ok && u.Kind() == types.String {
+		raw, _ := model.Eval(v, true).(z3.BV).AsBigUnsigned()
+		return strconv.Quote(rawStringValue(raw))
+	}
+	return model.Eval(v, true).String()
+}
+
+// inBounds tells if 0 <= low <= high <= len(s), slicing and indexing panic otherwise.
+func (ctx *EncodingContext) inBounds(low z3.Int, high z3.Int, s *String) z3.Bool {
+	return ctx.FromInt(0, ctx.IntSort()).(z3.Int).LE(low).And(low.LE(high), high.LE(s.len))
+}
+
+// stringValue evaluates string in model.
+func (ctx *EncodingContext) stringValue(s *String, model *z3.Model) string {
+	n, _, _ := model.Eval(s.len, true).(z3.Int).AsInt64()
+	bytes := make([]byte, 0, n)
+	for k := int64(0); k < n; k++ {
+		b, _, _ := model.Eval(s.at(ctx.FromInt(k, ctx.IntSort()).(z3.Int)), true).(z3.BV).AsUint64()
+		bytes = append(bytes, byte(b))
+	}
+	return string(bytes)
+}
+
+// stringValues evaluates string parameters and results of fn in model, they can't be read from model directly.
+func (ctx *EncodingContext) stringValues(fn *ssa.Function, model *z3.Model) map[string]string {
+	values := make(map[string]string)
+	for _, p := range fn.Params {
+		if s, ok := ctx.vars[p.Name()].(*String); ok {
+			values[p.Name()] = ctx.stringValue(s, model)
+		}
+	}
+	result := ctx.vars[resultSpecialVar]
+	for i := 0; i < fn.Signature.Results().Len(); i++ {
+		r := result
+		if tuple, ok := result.(*Tuple); ok {
+			r = tuple.elems[i]
+		}
+		if s, ok := r.(*String); ok {
+			values[resultVar(fn.Signature, i)] = ctx.stringValue(s, model)
+		}
+	}
+	return values
+}
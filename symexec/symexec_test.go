@@ -1,8 +1,14 @@
 package symexec
 
 import (
+	"fmt"
+	"go/types"
+	"math"
 	"os"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -23,17 +29,126 @@ func checkStatic(t *testing.T, shouldFail []string, filename string) {
 	}
 }
 
-func checkDynamic(t *testing.T, shouldFail []string, filename string) {
-	testcases := AnalyzeFileDynamic(filename)
-	for fn, tc := range testcases {
-		if tc != nil && slices.Contains(shouldFail, functionName(fn)) {
+func checkDynamic(t *testing.T, shouldFail []string, filename string) *PackageResult {
+	return checkDynamicWith(t, Options{}, shouldFail, filename)
+}
+
+// checkDynamicWith is checkDynamic with options, e.g. integers encoding.
+func checkDynamicWith(t *testing.T, opts Options, shouldFail []string, filename string) *PackageResult {
+	opts.Logger = NewLogger(os.Stdout, LogDebug, false)
+	r, err := NewAnalyzer(opts).AnalyzeFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fr := range r.Functions {
+		fn := fr.Function
+		if fr.Testcases != nil && slices.Contains(shouldFail, functionName(fn)) {
 			t.Errorf("'%s' should fail", fn)
 		}
-		if tc == nil && !slices.Contains(shouldFail, functionName(fn)) {
+		if fr.Testcases == nil && !slices.Contains(shouldFail, functionName(fn)) {
 			t.Errorf("'%s' should succeed", fn)
 		}
 	}
-	GenerateTests(filename, testcases)
+	if err := GenerateTests(r, GenerateOptions{}); err != nil {
+		t.Error(err)
+	}
+	return r
+}
+
+// checkCovered checks that every block of every function is covered.
+func checkCovered(t *testing.T, opts Options, filename string) {
+	opts.Logger = NewLogger(os.Stdout, LogDebug, false)
+	r, err := NewAnalyzer(opts).AnalyzeFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUncovered(t, r, nil)
+}
+
+// checkUncovered checks that every block of every function is covered except the given number of unreachable blocks of functions.
+func checkUncovered(t *testing.T, r *PackageResult, unreachable map[string]int) {
+	for _, fr := range r.Functions {
+		covered, all := fr.Coverage.CoveredBlocks()
+		if want := all - unreachable[FunctionName(fr.Function)]; covered != want {
+			t.Errorf("'%s': got %d/%d blocks covered; want %d", fr.Function, covered, all, want)
+		}
+	}
+}
+
+// testcasesOf returns testcases of function with name like 'Type.Method'.
+func testcasesOf(t *testing.T, r *PackageResult, name string) []Testcase {
+	for _, fr := range r.Functions {
+		if FunctionName(fr.Function) == name {
+			return fr.Testcases
+		}
+	}
+	t.Fatalf("function '%s' not found", name)
+	return nil
+}
+
+// checkResults checks that result of every testcase of function that doesn't panic is the result of Go code for its inputs.
+func checkResults(t *testing.T, r *PackageResult, name string, want func(vars map[string]string) int64) {
+	t.Helper()
+	for _, fr := range r.Functions {
+		fn := fr.Function
+		if FunctionName(fn) != name {
+			continue
+		}
+		for _, tc := range fr.Testcases {
+			if tc.panics {
+				continue
+			}
+			vars := testcaseVars(tc)
+			got := modelInt(t, vars, resultVar(fn.Signature, 0), fn.Signature.Results().At(0).Type())
+			if w := want(vars); got != w {
+				t.Errorf("%s: got %d for inputs %v; want %d", name, got, vars, w)
+			}
+		}
+		return
+	}
+	t.Fatalf("function '%s' not found", name)
+}
+
+// modelInt is the value of integer variable in model, like in generated tests.
+func modelInt(t *testing.T, vars map[string]string, name string, typ types.Type) int64 {
+	t.Helper()
+	code, err := initValue("v", vars[name], typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	literal := strings.SplitN(code, "\n", 2)[0]
+	literal = literal[strings.Index(literal, ":= ")+3:]
+	if i := strings.Index(literal, "("); i >= 0 {
+		literal = strings.TrimSuffix(literal[i+1:], ")")
+	}
+	if v, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return v
+	}
+	v, err := strconv.ParseUint(literal, 10, 64)
+	if err != nil {
+		t.Fatalf("'%s' is not an integer: %s", code, err)
+	}
+	return int64(v)
+}
+
+// modelFloat32 is the value of finite float32 variable in model, like in generated tests.
+func modelFloat32(t *testing.T, vars map[string]string, name string) float32 {
+	t.Helper()
+	code, err := initValue("v", vars[name], types.Typ[types.Float32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := strings.Index(code, "uint32(0x"); i >= 0 {
+		bits, err := strconv.ParseUint(code[i+len("uint32(0x"):strings.Index(code, ")")], 16, 32)
+		if err != nil {
+			t.Fatalf("'%s' has invalid bits: %s", code, err)
+		}
+		return math.Float32frombits(uint32(bits))
+	}
+	if strings.Contains(code, "math.") {
+		t.Fatalf("'%s' is not finite", code)
+	}
+	return 0
 }
 
 func TestStatic_Arrays(t *testing.T) {
@@ -76,38 +191,240 @@ func TestDynamic_SoftConstraints(t *testing.T) {
 	checkDynamic(t, []string{}, "softconstraints.go")
 }
 
+func TestDynamic_Primitives_Conversions(t *testing.T) {
+	for _, opts := range []Options{{Integers: IntegersMath}, {Integers: IntegersBitVector}} {
+		r := checkDynamicWith(t, opts, []string{}, "primitives/conversions.go")
+		checkUncovered(t, r, nil)
+		// integers wrap around to the width of result, floats are truncated toward zero
+		checkResults(t, r, "Conversions.Narrow", func(vars map[string]string) int64 {
+			a := modelInt(t, vars, "a", types.Typ[types.Int])
+			b := int8(a)
+			if a > 0 && b < 0 {
+				return 1
+			}
+			return int64(b)
+		})
+		checkResults(t, r, "Conversions.SignChange", func(vars map[string]string) int64 {
+			b := uint32(int32(modelInt(t, vars, "a", types.Typ[types.Int32])))
+			if b > 1<<31 {
+				return 1
+			}
+			return int64(b)
+		})
+		checkResults(t, r, "Conversions.ToUnsigned", func(vars map[string]string) int64 {
+			u := uint16(modelFloat32(t, vars, "f"))
+			if u == 300 {
+				return 1
+			}
+			return int64(u)
+		})
+	}
+}
+
 func TestDynamic_Primitives_Doubles(t *testing.T) {
 	checkDynamic(t, []string{}, "primitives/doubles.go")
 }
 
+func TestDynamic_Primitives_Floats(t *testing.T) {
+	checkDynamic(t, []string{}, "primitives/floats.go")
+}
+
 func TestDynamic_Primitives_Overflow(t *testing.T) {
 	checkDynamic(t, []string{}, "primitives/overflow.go")
 }
 
+func TestDynamic_Primitives_Strings(t *testing.T) {
+	checkDynamic(t, []string{}, "primitives/strings.go")
+}
+
+func TestDynamic_Primitives_StoredStrings(t *testing.T) {
+	checkCovered(t, Options{}, "primitives/storedStrings.go")
+}
+
+func TestDynamic_Primitives_Wraparound(t *testing.T) {
+	checkCovered(t, Options{Integers: IntegersBitVector}, "primitives/wraparound.go")
+}
+
+func TestDynamic_SoftConstraints_BitVector(t *testing.T) {
+	checkCovered(t, Options{Integers: IntegersBitVector}, "softconstraints.go")
+}
+
 func TestDynamic_Operators_Bit(t *testing.T) {
 	checkDynamic(t, []string{}, "operators/bit.go")
 }
 
+func TestDynamic_Operators_Division(t *testing.T) {
+	for _, opts := range []Options{{Integers: IntegersMath}, {Integers: IntegersBitVector}} {
+		r := checkDynamicWith(t, opts, []string{}, "operators/division.go")
+		checkUncovered(t, r, nil)
+		// quotients are truncated toward zero and remainders have the sign of dividend, unlike in SMT
+		checkResults(t, r, "Division.Truncate", func(vars map[string]string) int64 {
+			if a := modelInt(t, vars, "a", types.Typ[types.Int]); a/2 == -3 {
+				return a
+			}
+			return 0
+		})
+		checkResults(t, r, "Division.RemainderSign", func(vars map[string]string) int64 {
+			if a, b := modelInt(t, vars, "a", types.Typ[types.Int]), modelInt(t, vars, "b", types.Typ[types.Int]); b != 0 && a%b == -1 {
+				return -1
+			}
+			return 0
+		})
+		checkResults(t, r, "Division.NegativeDivisor", func(vars map[string]string) int64 {
+			if a := modelInt(t, vars, "a", types.Typ[types.Int]); a > 0 && a/-3 == -2 && a%-3 == 1 {
+				return 1
+			}
+			return 0
+		})
+		checkResults(t, r, "Division.Unsigned", func(vars map[string]string) int64 {
+			a, b := uint8(modelInt(t, vars, "a", types.Typ[types.Uint8])), uint8(modelInt(t, vars, "b", types.Typ[types.Uint8]))
+			if a%b == 7 {
+				return int64(a / b)
+			}
+			return 0
+		})
+		// division by zero panics
+		for _, name := range []string{"Division.ByZero", "Division.Unsigned"} {
+			if !slices.ContainsFunc(testcasesOf(t, r, name), func(tc Testcase) bool { return tc.panics }) {
+				t.Errorf("%s: no panic testcase with integers %s", name, opts.Integers)
+			}
+		}
+	}
+}
+
 func TestDynamic_Objects_RecursiveStruct(t *testing.T) {
 	checkDynamic(t, []string{}, "objects/recursiveStruct.go")
 }
 
 func TestDynamic_Objects_WithPrimitives(t *testing.T) {
-	checkDynamic(t, []string{}, "objects/withPrimitives.go")
+	// fmt.Sprintf is external
+	checkDynamic(t, []string{"String"}, "objects/withPrimitives.go")
 }
 
 func TestDynamic_Objects_WithReference(t *testing.T) {
 	checkDynamic(t, []string{}, "objects/withReference.go")
 }
 
+func TestDynamic_Objects_Allocation(t *testing.T) {
+	r := checkDynamic(t, []string{}, "objects/allocation.go")
+	// new objects are zero and never alias input pointers
+	checkUncovered(t, r, map[string]int{"Allocation.NewInt": 1, "Allocation.CompositeLiteral": 1, "Allocation.NestedZeroValue": 1,
+		"Allocation.FreshAddress": 2, "Allocation.CompareWithNew": 1})
+}
+
+func TestDynamic_Objects_Maps(t *testing.T) {
+	r := checkDynamic(t, []string{}, "objects/maps.go")
+	// input maps have only keys used by analyzed code, so non-empty NilMap and Delete of other keys are unreachable
+	checkUncovered(t, r, map[string]int{"Maps.NilMap": 1, "Maps.MakeAndUpdate": 1, "Maps.Delete": 2})
+}
+
 func TestDynamic_Invokes_SimpleCalls(t *testing.T) {
 	checkDynamic(t, []string{}, "invokes/simpleCalls.go")
 }
 
+func TestDynamic_Invokes_Interfaces(t *testing.T) {
+	r := checkDynamic(t, []string{}, "invokes/interfaces.go")
+	checkUncovered(t, r, map[string]int{"Interfaces.MakeShape": 1})
+	dispatched := make(map[string]bool)
+	for _, tc := range testcasesOf(t, r, "Interfaces.Dispatch") {
+		dispatched[fmt.Sprint(tc.ifaces["s"])] = true
+	}
+	for _, want := range []string{"<nil>", "*command-line-arguments.Rect", "*command-line-arguments.Square", "*command-line-arguments.Triangle"} {
+		if !dispatched[want] {
+			t.Errorf("Dispatch: no testcase with 's' of type '%s'", want)
+		}
+	}
+}
+
+func TestDynamic_Invokes_DynamicTypes(t *testing.T) {
+	checkCovered(t, Options{}, "invokes/dynamicTypes.go")
+}
+
+func TestDynamic_Invokes_Closures(t *testing.T) {
+	r := checkDynamic(t, []string{}, "invokes/closures.go")
+	checkUncovered(t, r, nil)
+	// calls of nil input function panic
+	if !slices.ContainsFunc(testcasesOf(t, r, "Closures.Unknown"), func(tc Testcase) bool { return tc.panics }) {
+		t.Error("Unknown: no panic testcase")
+	}
+}
+
 func TestDynamic_Flow_Loops(t *testing.T) {
 	checkDynamic(t, []string{}, "flow/loops.go")
 }
 
+func TestDynamic_Flow_Panics(t *testing.T) {
+	r := checkDynamic(t, []string{}, "flow/panics.go")
+	// recover blocks are unreachable if deferred functions don't recover
+	checkUncovered(t, r, map[string]int{"Panics.Deferred": 1, "Panics.Order": 1, "Panics.DeferredArgs": 1, "Panics.NotRecovered": 1})
+	for name, want := range map[string]bool{"Panics.Explicit": true, "Panics.Propagated": true, "Panics.NotRecovered": true, "Panics.Recovered": false, "Panics.RecoveredValue": false} {
+		panics := slices.ContainsFunc(testcasesOf(t, r, name), func(tc Testcase) bool {
+			return tc.panics
+		})
+		if panics != want {
+			t.Errorf("%s: got panic testcase %v; want %v", name, panics, want)
+		}
+	}
+}
+
+func TestDynamic_Flow_Goroutines(t *testing.T) {
+	r := checkDynamic(t, []string{}, "flow/goroutines.go")
+	// select without default panics if no case is chosen
+	checkUncovered(t, r, map[string]int{"Goroutines.Select": 1})
+	// nobody receives from input channel of send
+	deadlocks := []string{"send", "Goroutines.Deadlock"}
+	for _, fr := range r.Functions {
+		deadlock := slices.ContainsFunc(fr.Testcases, Testcase.Deadlock)
+		if want := slices.Contains(deadlocks, FunctionName(fr.Function)); deadlock != want {
+			t.Errorf("%s: got deadlock testcase %v; want %v", fr.Function, deadlock, want)
+		}
+	}
+}
+
+func TestDynamic_Flow_RuntimePanics(t *testing.T) {
+	r := checkDynamic(t, []string{}, "flow/runtimePanics.go")
+	checkUncovered(t, r, nil)
+	for _, name := range []string{"NilMapWrite", "BadAssert", "DoubleClose", "NilCall", "StringSlice", "MakeSlice"} {
+		if !slices.ContainsFunc(testcasesOf(t, r, "RuntimePanics."+name), func(tc Testcase) bool { return tc.panics }) {
+			t.Errorf("%s: no panic testcase", name)
+		}
+	}
+}
+
+func TestDynamic_Flow_GoroutinePanic(t *testing.T) {
+	opts := Options{
+		Logger:  NewLogger(os.Stdout, LogDebug, false),
+		Include: []*regexp.Regexp{regexp.MustCompile(`^Goroutines\.GoPanic$`)},
+	}
+	r, err := NewAnalyzer(opts).AnalyzeFile("flow/goroutines.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Functions) != 1 {
+		t.Fatalf("got %d functions; want 1", len(r.Functions))
+	}
+	var goPanics, returns int
+	for _, tc := range r.Functions[0].Testcases {
+		switch {
+		case tc.goPanic && tc.panics:
+			goPanics++
+		case !tc.panics && !tc.deadlock:
+			returns++
+		default:
+			t.Errorf("unexpected testcase with path %v", tc.path)
+		}
+	}
+	if goPanics != 1 || returns != 1 {
+		t.Errorf("got %d goroutine panics and %d returns; want 1 and 1", goPanics, returns)
+	}
+}
+
+func TestDynamic_Flow_Ranges(t *testing.T) {
+	r := checkDynamic(t, []string{}, "flow/ranges.go")
+	// length of input map is the number of keys used by analyzed code, there are none before range
+	checkUncovered(t, r, map[string]int{"Ranges.Find": 1, "Ranges.Sum": 1, "Ranges.Count": 1})
+}
+
 func TestDynamic_Flow_Recursion(t *testing.T) {
 	checkDynamic(t, []string{}, "flow/recursion.go")
 }
@@ -128,6 +445,14 @@ func TestDynamic_Arrays_PrimitiveArrays(t *testing.T) {
 	checkDynamic(t, []string{}, "arrays/primitiveArrays.go")
 }
 
+func TestDynamic_Arrays_Slices(t *testing.T) {
+	checkDynamic(t, []string{}, "arrays/slices.go")
+}
+
+func TestDynamic_Arrays_Fixed(t *testing.T) {
+	checkDynamic(t, []string{}, "arrays/fixed.go")
+}
+
 func TestDynamic_Mocks_Sqrt(t *testing.T) {
 	checkDynamic(t, []string{}, "mocks/sqrt.go")
 }
@@ -12,7 +12,13 @@ type Complex struct {
 	sort z3.Sort
 }
 
+// String is a sequence of bytes with symbolic length.
+// Bytes of constants, concatenations and substrings are not stored, they are computed by index.
 type String struct {
+	len z3.Int
+	at  func(i z3.Int) z3.BV
+	// max is the upper bound of length.
+	max  int
 	sort z3.Sort
 }
 
@@ -29,12 +35,44 @@ type SymArray struct {
 	sort z3.Sort
 }
 
+// FixedArray is the address of backing array of constant length, array values are copied to new backing arrays.
+type FixedArray struct {
+	addr z3.Uninterpreted
+	t    string
+	sort z3.Sort
+}
+
 type SymStruct struct {
 	addr z3.Uninterpreted
 	t    string
 	sort z3.Sort
 }
 
+type SymMap struct {
+	addr z3.Uninterpreted
+	t    string
+	sort z3.Sort
+}
+
+// Interface is the address of a box holding the value, the dynamic type is stored separately by address.
+type Interface struct {
+	addr z3.Uninterpreted
+	t    string
+	sort z3.Sort
+}
+
+// Closure is the address of function value, its target function and bindings are stored separately by address.
+type Closure struct {
+	addr z3.Uninterpreted
+	t    string
+	sort z3.Sort
+}
+
+// Tuple is the result of function with multiple return values, it has no sort of its own.
+type Tuple struct {
+	elems []SymValue
+}
+
 func (c *Complex) Sort() z3.Sort {
 	return c.sort
 }
@@ -51,6 +89,26 @@ func (sa *SymArray) Sort() z3.Sort {
 	return sa.sort
 }
 
+func (fa *FixedArray) Sort() z3.Sort {
+	return fa.sort
+}
+
 func (ss *SymStruct) Sort() z3.Sort {
 	return ss.sort
 }
+
+func (m *SymMap) Sort() z3.Sort {
+	return m.sort
+}
+
+func (i *Interface) Sort() z3.Sort {
+	return i.sort
+}
+
+func (c *Closure) Sort() z3.Sort {
+	return c.sort
+}
+
+func (t *Tuple) Sort() z3.Sort {
+	return z3.Sort{}
+}
@@ -0,0 +1,65 @@
+package main
+
+type Fixed struct{}
+
+func (f *Fixed) Get(a [3]int, i int) int {
+	if a[i] > 10 {
+		return 1
+	}
+	return a[i]
+}
+
+func (f *Fixed) Copy(a [3]int) int {
+	b := a
+	b[0] = 7
+	if a[0] == 7 {
+		return 1
+	}
+	return b[0] - a[0]
+}
+
+func (f *Fixed) Equal(a [2]int, b [2]int) bool {
+	return a == b
+}
+
+func (f *Fixed) Key(k [4]byte) int {
+	if k == [4]byte{1, 2, 3, 4} {
+		return 1
+	}
+	if k[3] == 255 {
+		return 2
+	}
+	return 0
+}
+
+func (f *Fixed) Reverse(a [3]int) [3]int {
+	var r [3]int
+	for i := range a {
+		r[len(a)-1-i] = a[i]
+	}
+	return r
+}
+
+func (f *Fixed) Trace(m [2][2]int) int {
+	t := m[0][0] + m[1][1]
+	if t > 0 && m[0][1] == m[1][0] {
+		return t
+	}
+	return 0
+}
+
+func (f *Fixed) Identity(n int) [2][2]int {
+	var m [2][2]int
+	m[0][0] = n
+	m[1][1] = n
+	return m
+}
+
+func (f *Fixed) Zero(a [2]int) bool {
+	return a == [2]int{}
+}
+
+func (f *Fixed) Slice(a [4]int, i int) int {
+	s := a[1:]
+	return s[i]
+}
@@ -0,0 +1,82 @@
+package main
+
+type Slices struct{}
+
+func (s *Slices) Window(i int, j int) int {
+	a := []int{1, 2, 3, 4}
+	w := a[i:j]
+	if len(w) > 2 {
+		return w[2]
+	}
+	return len(w)
+}
+
+func (s *Slices) Alias(x int) int {
+	a := make([]int, 3)
+	b := a[1:]
+	b[0] = x
+	if a[1] == 5 {
+		return 1
+	}
+	return a[2]
+}
+
+func (s *Slices) Capacity(n int) int {
+	a := make([]int, 2, 4)
+	b := a[:n]
+	return cap(b) - len(b)
+}
+
+func (s *Slices) Limit(k int) int {
+	a := make([]int, 4)
+	b := a[1:2:k]
+	return cap(b)
+}
+
+func (s *Slices) AppendReuse(x int) int {
+	a := make([]int, 1, 2)
+	b := append(a, x)
+	c := append(a, 7)
+	if b[1] == c[1] {
+		return 1
+	}
+	return 0
+}
+
+func (s *Slices) AppendGrow(x int) int {
+	a := make([]int, 1)
+	b := append(a, x, x)
+	b[0] = 3
+	if b[2] > 10 {
+		return a[0] + len(b)
+	}
+	return cap(b) - len(b) + a[0]
+}
+
+func (s *Slices) Grow(n int) int {
+	if n < 0 || n > 5 {
+		return -1
+	}
+	a := make([]byte, n)
+	a = append(a, 'a')
+	if cap(a) > 8 {
+		return cap(a)
+	}
+	return 0
+}
+
+func (s *Slices) Copy(x int, n int) int {
+	if n < 0 || n > 4 {
+		return -1
+	}
+	src := []int{x, x + 1, x + 2}
+	dst := make([]int, n)
+	c := copy(dst, src)
+	if c == 3 && dst[2] > 10 {
+		return 1
+	}
+	if c > 0 && dst[c-1] == 0 {
+		return 2
+	}
+	return 0
+}
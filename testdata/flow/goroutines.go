@@ -0,0 +1,107 @@
+package main
+
+type Goroutines struct{}
+
+func send(ch chan<- int, x int) {
+	ch <- x
+}
+
+func (g *Goroutines) Unbuffered(x int) int {
+	ch := make(chan int)
+	go send(ch, x)
+	if <-ch > 10 {
+		return 1
+	}
+	return 0
+}
+
+func (g *Goroutines) Buffered(x int) int {
+	ch := make(chan int, 2)
+	ch <- x
+	ch <- x + 1
+	return <-ch - <-ch
+}
+
+func (g *Goroutines) Captured(x int) int {
+	ch := make(chan int)
+	go func() {
+		ch <- x * 2
+	}()
+	return <-ch
+}
+
+func (g *Goroutines) Shared(x int) int {
+	res := 0
+	done := make(chan bool)
+	go func() {
+		res = x + 1
+		done <- true
+	}()
+	<-done
+	return res
+}
+
+func (g *Goroutines) Range(n int) int {
+	ch := make(chan int, 2)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	sum := n
+	for v := range ch {
+		sum += v
+	}
+	return sum
+}
+
+func (g *Goroutines) Select(x int) int {
+	a := make(chan int, 1)
+	b := make(chan int, 1)
+	if x > 0 {
+		a <- x
+	} else {
+		b <- x
+	}
+	select {
+	case v := <-a:
+		return v
+	case v := <-b:
+		return -v
+	}
+}
+
+func (g *Goroutines) Default(x int) int {
+	ch := make(chan int, 1)
+	if x > 0 {
+		ch <- x
+	}
+	select {
+	case v := <-ch:
+		return v
+	default:
+		return 0
+	}
+}
+
+func (g *Goroutines) Deadlock(x int) int {
+	ch := make(chan int)
+	if x > 0 {
+		// nobody receives
+		ch <- x
+	}
+	return x
+}
+
+func (g *Goroutines) GoPanic(k int) int {
+	done := make(chan bool)
+	go func() {
+		if k == 7 {
+			panic("seven")
+		}
+		done <- true
+	}()
+	<-done
+	return k + 1
+}
@@ -0,0 +1,89 @@
+package main
+
+type Panics struct{}
+
+func mustPositive(x int) int {
+	if x <= 0 {
+		panic("not positive")
+	}
+	return x
+}
+
+func add(res *int, x int) {
+	*res += x
+}
+
+func (p *Panics) Explicit(x int) int {
+	if x < 0 {
+		panic("negative")
+	}
+	return x
+}
+
+func (p *Panics) Propagated(x int) int {
+	if mustPositive(x) > 10 {
+		return 1
+	}
+	return 0
+}
+
+func (p *Panics) Deferred(x int) (res int) {
+	defer func() {
+		res += 10
+	}()
+	if x > 5 {
+		return 1
+	}
+	return 0
+}
+
+func (p *Panics) Order(x int) (res int) {
+	defer func() {
+		res *= 2
+	}()
+	defer func() {
+		res += 1
+	}()
+	if x > 0 {
+		return x
+	}
+	return 0
+}
+
+func (p *Panics) DeferredArgs(x int) (res int) {
+	// argument is evaluated by defer statement
+	defer add(&res, x)
+	x = 100
+	return x
+}
+
+func (p *Panics) Recovered(x int) (res int) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = -1
+		}
+	}()
+	if x > 5 {
+		panic(x)
+	}
+	return x
+}
+
+func (p *Panics) RecoveredValue(x int) (res int) {
+	defer func() {
+		if r, ok := recover().(int); ok && r > 10 {
+			res = 1
+		}
+	}()
+	panic(x)
+}
+
+func (p *Panics) NotRecovered(x int) (res int) {
+	defer func() {
+		res = 1
+	}()
+	if x == 0 {
+		panic(x)
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package main
+
+type Ranges struct{}
+
+func (r *Ranges) Find(m map[int]int, key int) int {
+	if len(m) > 2 {
+		return -1
+	}
+	for k, v := range m {
+		if k == key {
+			return v
+		}
+	}
+	return 0
+}
+
+func (r *Ranges) Sum(m map[int]int) int {
+	if len(m) > 2 {
+		return -1
+	}
+	sum := 0
+	for _, v := range m {
+		sum += v
+	}
+	if sum > 10 {
+		return 1
+	}
+	return 0
+}
+
+func (r *Ranges) Count(m map[int]bool) int {
+	if len(m) > 3 {
+		return -1
+	}
+	n := 0
+	for range m {
+		n++
+	}
+	return n
+}
+
+func (r *Ranges) Local(x int) int {
+	m := make(map[int]int)
+	m[1] = x
+	m[2] = 3
+	sum := 0
+	for k, v := range m {
+		sum += k * v
+	}
+	return sum
+}
+
+func (r *Ranges) Runes(s string) int {
+	if len(s) > 4 {
+		return -1
+	}
+	n := 0
+	for _, c := range s {
+		if c > 127 {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Ranges) Index(s string) int {
+	if len(s) > 4 {
+		return -1
+	}
+	for i, c := range s {
+		if c == 'é' {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,47 @@
+package main
+
+type Counter interface {
+	Count() int
+}
+
+type Fixed struct {
+	N int
+}
+
+func (f *Fixed) Count() int {
+	return f.N
+}
+
+type RuntimePanics struct{}
+
+func (r *RuntimePanics) NilMapWrite(m map[int]int, k int) int {
+	m[k] = 1
+	return len(m)
+}
+
+func (r *RuntimePanics) BadAssert(x any) int {
+	f := x.(*Fixed)
+	return f.N
+}
+
+func (r *RuntimePanics) DoubleClose(twice bool) int {
+	ch := make(chan int)
+	close(ch)
+	if twice {
+		close(ch)
+	}
+	return 0
+}
+
+func (r *RuntimePanics) NilCall(c Counter) int {
+	return c.Count()
+}
+
+func (r *RuntimePanics) StringSlice(s string, i int) string {
+	return s[i:]
+}
+
+func (r *RuntimePanics) MakeSlice(n int) int {
+	a := make([]int, n)
+	return len(a)
+}
@@ -0,0 +1,88 @@
+package main
+
+type Counter struct {
+	count int
+}
+
+func (c *Counter) Inc(n int) int {
+	c.count += n
+	return c.count
+}
+
+func double(x int) int {
+	return x * 2
+}
+
+func negate(x int) int {
+	return -x
+}
+
+func apply(f func(int) int, x int) int {
+	return f(x)
+}
+
+type Closures struct{}
+
+func (c *Closures) Captured(x int) int {
+	limit := 10
+	check := func(y int) bool {
+		return y > limit
+	}
+	if check(x) {
+		return 1
+	}
+	return 0
+}
+
+func (c *Closures) Modified(x int) int {
+	sum := 0
+	add := func(y int) {
+		sum += y
+	}
+	add(x)
+	add(1)
+	if sum == 5 {
+		return 1
+	}
+	return 0
+}
+
+func (c *Closures) Callback(x int, neg bool) int {
+	f := double
+	if neg {
+		f = negate
+	}
+	if apply(f, x) > 10 {
+		return 1
+	}
+	return 0
+}
+
+func (c *Closures) MethodValue(x int) int {
+	counter := &Counter{}
+	inc := counter.Inc
+	inc(x)
+	if inc(x) == 4 {
+		return 1
+	}
+	return 0
+}
+
+func (c *Closures) Unknown(f func(int) int, x int) int {
+	if f(x) == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (c *Closures) UnknownTwice(f func(int) (int, error)) int {
+	a, err := f(1)
+	if err != nil {
+		return -1
+	}
+	b, _ := f(2)
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package main
+
+type Polygon interface {
+	Sides() int
+}
+
+type Meters int
+
+func (m Meters) Sides() int {
+	return int(m)
+}
+
+type Tri struct{}
+
+func (Tri) Sides() int {
+	return 3
+}
+
+type DynamicTypes struct{}
+
+func (d *DynamicTypes) LocalNamed(k int) int {
+	var p Polygon = Meters(k)
+	if p.Sides() == 3 {
+		return 1
+	}
+	return 0
+}
+
+func (d *DynamicTypes) NamedDispatch(p Polygon) int {
+	if p == nil {
+		return -1
+	}
+	switch p.Sides() {
+	case 3:
+		return 3
+	case 4:
+		return 4
+	}
+	return 0
+}
+
+func (d *DynamicTypes) ValueStruct(k int) int {
+	var p Polygon = Tri{}
+	if k > 0 {
+		p = Meters(k)
+	}
+	return p.Sides()
+}
+
+func (d *DynamicTypes) CompareValues(k int) int {
+	var a, b any = Tri{}, Tri{}
+	if k > 0 {
+		b = Meters(k)
+	}
+	if a == b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+type Shape interface {
+	Area() int
+	Sides() int
+}
+
+type Square struct {
+	side int
+}
+
+func (s *Square) Area() int {
+	return s.side * s.side
+}
+
+func (s *Square) Sides() int {
+	return 4
+}
+
+type Rect struct {
+	width, height int
+}
+
+func (r *Rect) Area() int {
+	return r.width * r.height
+}
+
+func (r *Rect) Sides() int {
+	return 4
+}
+
+type Triangle struct {
+	base, height int
+}
+
+func (tr *Triangle) Area() int {
+	return tr.base * tr.height / 2
+}
+
+func (tr *Triangle) Sides() int {
+	return 3
+}
+
+type Interfaces struct{}
+
+func (i *Interfaces) Dispatch(s Shape) int {
+	if s == nil {
+		return -1
+	}
+	if s.Sides() > 3 {
+		return 1
+	}
+	return 0
+}
+
+func (i *Interfaces) TypeSwitch(s Shape) int {
+	switch s := s.(type) {
+	case *Square:
+		return 1
+	case *Rect, *Triangle:
+		return s.Sides()
+	}
+	return 0
+}
+
+func (i *Interfaces) Boxed(x int) int {
+	var v any = x
+	if n, ok := v.(int); ok && n > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (i *Interfaces) Compare(x int, y int) int {
+	var a, b any = x, y
+	if a == b {
+		return 1
+	}
+	return 0
+}
+
+func (i *Interfaces) MakeShape(side int) int {
+	var s Shape = &Square{side: side}
+	if _, ok := s.(*Rect); ok {
+		// unreachable
+		return -1
+	}
+	return s.Area()
+}
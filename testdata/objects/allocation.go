@@ -0,0 +1,70 @@
+package main
+
+type Point struct {
+	X, Y int
+}
+
+type Segment struct {
+	From Point
+	To   *Point
+}
+
+type Allocation struct{}
+
+func (a *Allocation) NewInt(value int) int {
+	p := new(int)
+	if *p != 0 {
+		return -1
+	}
+	*p = value
+	return *p
+}
+
+func (a *Allocation) LocalAddress(value int) int {
+	x := value
+	p := &x
+	*p += 1
+	if x > 10 {
+		return x
+	}
+	return 0
+}
+
+func (a *Allocation) CompositeLiteral(x int) int {
+	p := &Point{X: x}
+	if p.Y != 0 {
+		return -1
+	}
+	p.Y = p.X * 2
+	return p.Y
+}
+
+func (a *Allocation) NestedZeroValue() int {
+	s := &Segment{}
+	if s.From.X != 0 || s.To != nil {
+		return -1
+	}
+	return 0
+}
+
+func (a *Allocation) FreshAddress(input *Point) int {
+	p := &Point{}
+	input.X = 1
+	if p.X != 0 {
+		// unreachable, new object can't alias input
+		return -1
+	}
+	if p == input {
+		return -2
+	}
+	return 0
+}
+
+func (a *Allocation) CompareWithNew(p *Point) int {
+	q := new(Point)
+	if p == q {
+		// unreachable, input pointer is never equal to new object
+		return -1
+	}
+	return 0
+}
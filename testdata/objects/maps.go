@@ -0,0 +1,55 @@
+package main
+
+type Maps struct{}
+
+func (m *Maps) Lookup(counts map[int]int, key int) int {
+	if counts[key] > 10 {
+		return 1
+	}
+	return 0
+}
+
+func (m *Maps) CommaOk(counts map[int]int, key int) int {
+	v, ok := counts[key]
+	if !ok {
+		return -1
+	}
+	if v == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (m *Maps) NilMap(counts map[int]bool) int {
+	if counts == nil {
+		return -1
+	}
+	if len(counts) == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (m *Maps) MakeAndUpdate(key int) int {
+	counts := make(map[int]int)
+	counts[key] = 1
+	counts[key] += 1
+	counts[key+1] = 5
+	if len(counts) != 2 {
+		// unreachable, keys are different
+		return -1
+	}
+	return counts[key]
+}
+
+func (m *Maps) Delete(counts map[int]int, key int) int {
+	delete(counts, key)
+	if _, ok := counts[key]; ok {
+		// unreachable, key was deleted
+		return -1
+	}
+	if len(counts) > 0 {
+		return 1
+	}
+	return 0
+}
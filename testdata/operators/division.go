@@ -0,0 +1,38 @@
+package main
+
+type Division struct{}
+
+func (d *Division) Truncate(a int) int {
+	if a/2 == -3 {
+		return a
+	}
+	return 0
+}
+
+func (d *Division) RemainderSign(a int, b int) int {
+	if b != 0 && a%b == -1 {
+		return -1
+	}
+	return 0
+}
+
+func (d *Division) NegativeDivisor(a int) int {
+	if a > 0 && a/-3 == -2 && a%-3 == 1 {
+		return 1
+	}
+	return 0
+}
+
+func (d *Division) ByZero(a int, b int) int {
+	if a/b > 1 {
+		return 1
+	}
+	return 0
+}
+
+func (d *Division) Unsigned(a uint8, b uint8) uint8 {
+	if a%b == 7 {
+		return a / b
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package main
+
+type Celsius float64
+
+type Level uint8
+
+type Conversions struct{}
+
+func (c *Conversions) Truncate(f float64) int {
+	i := int(f)
+	if f < 0 && i == -2 && float64(i) != f {
+		return 1
+	}
+	return 0
+}
+
+func (c *Conversions) FromUnsigned(u uint16) float32 {
+	f := float32(u)
+	if f > 65000 {
+		return 1
+	}
+	return f
+}
+
+func (c *Conversions) Narrow(a int) int8 {
+	b := int8(a)
+	if a > 0 && b < 0 {
+		return 1
+	}
+	return b
+}
+
+func (c *Conversions) SignChange(a int32) uint32 {
+	b := uint32(a)
+	if b > 1<<31 {
+		return 1
+	}
+	return b
+}
+
+func (c *Conversions) Runes(r rune) byte {
+	b := byte(r)
+	if r > 255 && b == 'a' {
+		return 1
+	}
+	return b
+}
+
+func (c *Conversions) Named(temp Celsius, l Level) int {
+	if int(temp) == 37 && Level(int(l)+1) == 0 {
+		return 1
+	}
+	return 0
+}
+
+func (c *Conversions) ToUnsigned(f float32) uint16 {
+	u := uint16(f)
+	if u == 300 {
+		return 1
+	}
+	return u
+}
@@ -0,0 +1,35 @@
+package main
+
+type FloatExamples struct{}
+
+func (f *FloatExamples) CompareSum(a, b float32) float32 {
+	z := a + b
+	if z > 5.6 {
+		return 1.0
+	}
+	return 0.0
+}
+
+func (f *FloatExamples) Widen(a float32) float64 {
+	d := float64(a)
+	if d > 0.1 {
+		return d
+	}
+	return 0.0
+}
+
+func (f *FloatExamples) Narrow(d float64) int {
+	a := float32(d)
+	if float64(a) != d {
+		return 1
+	}
+	return 0
+}
+
+func (f *FloatExamples) FromInt(i int) float32 {
+	a := float32(i)
+	if a > 1000 && a == float32(i-1) {
+		return 0
+	}
+	return a
+}
@@ -0,0 +1,56 @@
+package main
+
+type Person struct {
+	name string
+	age  int
+}
+
+type StoredStrings struct{}
+
+func (s *StoredStrings) MapKey(m map[string]int) int {
+	if m["a"] == 3 {
+		return 1
+	}
+	return 0
+}
+
+func (s *StoredStrings) MapValue(m map[int]string, k int) int {
+	if v, ok := m[k]; ok && v == "go" {
+		return 1
+	}
+	return 0
+}
+
+func (s *StoredStrings) Field(name string) int {
+	p := &Person{name: name}
+	if p.name == "x" {
+		return 1
+	}
+	return 0
+}
+
+func (s *StoredStrings) ZeroField(age int) string {
+	p := &Person{age: age}
+	if p.name == "" && age > 3 {
+		return p.name + "!"
+	}
+	return "?"
+}
+
+func (s *StoredStrings) Elements(a string) int {
+	names := []string{"x", a}
+	if names[1] == names[0] {
+		return 1
+	}
+	return 0
+}
+
+func (s *StoredStrings) Keys(a string, b string) int {
+	m := map[string]int{}
+	m[a] = 1
+	m[b] = 2
+	if m[a] == 2 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+type StringExamples struct{}
+
+func (s *StringExamples) Greeting(name string) string {
+	greeting := "Hello, " + name + "!"
+	if greeting == "Hello, gopher!" {
+		return "hi"
+	}
+	return greeting
+}
+
+func (s *StringExamples) Compare(a, b string) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func (s *StringExamples) FirstByte(str string) int {
+	if len(str) == 0 {
+		return -1
+	}
+	if str[0] == 'x' {
+		return 1
+	}
+	return 0
+}
+
+func (s *StringExamples) Suffix(str string) string {
+	if len(str) < 3 {
+		return ""
+	}
+	if str[len(str)-3:] == ".go" {
+		return str[:len(str)-3]
+	}
+	return str
+}
+
+func (s *StringExamples) LongConstant(str string) bool {
+	return str == "constant: longer than twenty bytes"
+}
@@ -0,0 +1,62 @@
+package main
+
+type Wraparound struct{}
+
+func (w *Wraparound) Increment(a int8) int8 {
+	b := a + 1
+	if b < a {
+		return -1
+	}
+	return b
+}
+
+func (w *Wraparound) Multiply(a int32) int32 {
+	if a > 0 && a*2 < 0 {
+		return -1
+	}
+	return a
+}
+
+func (w *Wraparound) Underflow(a uint32, b uint32) int {
+	if a-b > a {
+		return 1
+	}
+	return 0
+}
+
+func (w *Wraparound) Compare(a uint64) int {
+	if a > 1<<63 {
+		return 1
+	}
+	return 0
+}
+
+func (w *Wraparound) Shift(a uint8, n uint) uint8 {
+	s := a << n
+	if s == 0 && a != 0 {
+		return 1
+	}
+	return s
+}
+
+func (w *Wraparound) SignedShift(a int16) int16 {
+	if a>>15 == -1 {
+		return -1
+	}
+	return a >> 1
+}
+
+func (w *Wraparound) Truncate(a int) uint8 {
+	b := uint8(a)
+	if a > 255 && b == 0 {
+		return 1
+	}
+	return b
+}
+
+func (w *Wraparound) Negate(a int64) int64 {
+	if a < 0 && -a < 0 {
+		return 1
+	}
+	return ^a
+}